@@ -0,0 +1,1518 @@
+package bezsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 60 * time.Second
+
+type builder struct {
+	dialect           Dialect
+	databaseName      string
+	usedConfig        Config
+	table             string
+	cols              []string
+	query             Query
+	having            Query
+	joins             []join
+	compounds         []compound
+	ctes              []cte
+	params            []interface{}
+	paramNames        []string
+	insertValues      []string
+	multiInsertValues [][]string
+	insertColumns     []string
+	insertSelect      string
+	updateValues      []string
+	upsert            bool
+	upsertConflict    []string
+	upsertUpdate      []string
+	returning         []string
+	lockMode          LockMode
+	valuesError       error
+	lockWait          LockWait
+	limitBy           int
+	offsetBy          int
+	ordering          []orderBy
+	groupColumns      []string
+	parallel          bool
+	tx                *sql.Tx
+	insertedStructs   []reflect.Value
+	insertedKey       []int
+}
+
+func newBuilder(dialect Dialect) *builder {
+	db := builder{
+		dialect: dialect,
+		query:   Query{dialect: dialect},
+		having:  Query{dialect: dialect},
+	}
+	if dialect.ParamStyle() == NamedParams {
+		db.SetParamPrefix("param")
+	}
+	return &db
+}
+
+func (db *builder) SetParamPrefix(prefix string) {
+	db.query.SetParamPrefix(prefix)
+	db.having.SetParamPrefix(prefix + "Having")
+}
+
+func (db *builder) countExists(query string, params []interface{}) (bool, error) {
+	newDb, err := db.NewQuery()
+	if err != nil {
+		return false, err
+	}
+	res, close, err := newDb.RawQuery(query, params)
+	if err != nil {
+		return false, err
+	}
+	defer close()
+	for res.Next() {
+		var num int32
+		res.Scan(&num)
+		if num > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (db *builder) DoesTableExist(table string) (bool, error) {
+	query, params := db.dialect.TableExistsQuery(db.GetConfig(), table)
+	return db.countExists(query, params)
+}
+
+func (db *builder) RunParallel() {
+	db.parallel = true
+}
+
+func (db *builder) DoesColumnExist(table string, field string) (bool, error) {
+	query, params := db.dialect.ColumnExistsQuery(db.GetConfig(), table, field)
+	return db.countExists(query, params)
+}
+
+func (db *builder) GetConfig() Config {
+	return db.usedConfig
+}
+
+func (db *builder) RawQuery(query string, params []interface{}) (*sql.Rows, context.CancelFunc, error) {
+	return db.RawQueryContext(context.Background(), query, params)
+}
+
+func (db *builder) RawQueryContext(ctx context.Context, query string, params []interface{}) (*sql.Rows, context.CancelFunc, error) {
+	db.params = params
+	db.paramNames = nil
+	return db.executeQuery(ctx, query)
+}
+
+func (db *builder) RawNonQuery(query string, params []interface{}) (sql.Result, error) {
+	return db.RawNonQueryContext(context.Background(), query, params)
+}
+
+func (db *builder) RawNonQueryContext(ctx context.Context, query string, params []interface{}) (sql.Result, error) {
+	db.params = params
+	db.paramNames = nil
+	return db.executeNonQuery(ctx, query)
+}
+
+func (db *builder) Table(table string) {
+	db.table = table
+}
+
+func (db *builder) TableSub(subDb DB, alias string) {
+	db.table = fmt.Sprintf(" (%s) %s ", subDb.GenerateSelect(), db.checkReserved(alias))
+	db.params = append(db.params, subDb.getParams()...)
+	db.paramNames = append(db.paramNames, subDb.getParamNames()...)
+}
+
+func (db *builder) getParams() []interface{} {
+	return db.params
+}
+
+func (db *builder) getParamNames() []string {
+	return db.paramNames
+}
+
+func (db *builder) checkReserved(word string) string {
+	reservedWords := []string{
+		"select",
+		"insert",
+		"delete",
+		"update",
+		"where",
+		"table",
+		"join",
+		"order",
+		"read",
+		"check"}
+	reservedWords = append(reservedWords, dialectReservedWords(db.dialect)...)
+	if strings.Contains(word, ".") {
+		wordParts := strings.Split(word, ".")
+		escapedParts := []string{}
+		for _, wordPart := range wordParts {
+			for _, reservedWord := range reservedWords {
+				if reservedWord == wordPart {
+					wordPart = db.dialect.QuoteIdentifier(wordPart)
+					break
+				}
+			}
+			escapedParts = append(escapedParts, wordPart)
+		}
+		return strings.Join(escapedParts, ".")
+	} else {
+		for _, reservedWord := range reservedWords {
+			if reservedWord == word {
+				word = db.dialect.QuoteIdentifier(word)
+				break
+			}
+		}
+		return word
+	}
+}
+
+func (db *builder) Cols(cols []string) {
+	escapedCols := []string{}
+	for _, col := range cols {
+		escapedCols = append(escapedCols, db.checkReserved(col))
+	}
+	db.cols = escapedCols
+}
+
+func (db *builder) Count(col string, alias string) string {
+	return fmt.Sprintf("COUNT(%s) %s", db.checkReserved(col), db.checkReserved(alias))
+}
+
+func (db *builder) Sum(col string, alias string) string {
+	return fmt.Sprintf("SUM(%s) %s", db.checkReserved(col), db.checkReserved(alias))
+}
+
+func (db *builder) Avg(col string, alias string) string {
+	return fmt.Sprintf("AVG(%s) %s", db.checkReserved(col), db.checkReserved(alias))
+}
+
+func (db *builder) Max(col string, alias string) string {
+	return fmt.Sprintf("MAX(%s) %s", db.checkReserved(col), db.checkReserved(alias))
+}
+
+func (db *builder) Min(col string, alias string) string {
+	return fmt.Sprintf("MIN(%s) %s", db.checkReserved(col), db.checkReserved(alias))
+}
+
+func (db *builder) NewQuery() (DB, error) {
+	newDB := newBuilder(db.dialect)
+	_, err := newDB.connect(db.databaseName, db.usedConfig)
+	if err != nil {
+		return nil, err
+	}
+	newDB.tx = db.tx
+	return newDB, nil
+}
+
+func (db *builder) Clone() (DB, error) {
+	newDB := newBuilder(db.dialect)
+	_, err := newDB.connect(db.databaseName, db.usedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	newDB.table = db.table
+	newDB.cols = db.cols
+	newDB.groupColumns = db.groupColumns
+	newDB.insertColumns = db.insertColumns
+	newDB.insertValues = db.insertValues
+	newDB.insertSelect = db.insertSelect
+	newDB.updateValues = db.updateValues
+	newDB.upsert = db.upsert
+	newDB.upsertConflict = db.upsertConflict
+	newDB.upsertUpdate = db.upsertUpdate
+	newDB.returning = db.returning
+	newDB.lockMode = db.lockMode
+	newDB.valuesError = db.valuesError
+	newDB.lockWait = db.lockWait
+	newDB.joins = db.joins
+	newDB.compounds = db.compounds
+	newDB.ctes = db.ctes
+	newDB.multiInsertValues = db.multiInsertValues
+	newDB.ordering = db.ordering
+	newDB.limitBy = db.limitBy
+	newDB.offsetBy = db.offsetBy
+	newDB.params = db.params
+	newDB.paramNames = db.paramNames
+	newDB.query = db.query
+	newDB.having = db.having
+	newDB.parallel = db.parallel
+	newDB.tx = db.tx
+	newDB.insertedStructs = db.insertedStructs
+	newDB.insertedKey = db.insertedKey
+
+	return newDB, err
+
+}
+
+func (db *builder) openConnection() (*sql.DB, error) {
+	return db.dialect.Connect(db.usedConfig)
+}
+
+func (db *builder) connect(databaseName string, config Config) (bool, error) {
+	db.databaseName = databaseName
+	db.usedConfig = config
+	if _, exists := openConnections[databaseName]; !exists {
+
+		dbCon, err := db.openConnection()
+		if err != nil {
+			return false, err
+		}
+		openConnections[databaseName] = dbCon
+	}
+
+	return true, nil
+}
+
+// returns the placeholder for the next insert or update value, SQL Server values are named separately from the where conditions
+func (db *builder) addValueParam(params *[]interface{}, paramNames *[]string, val interface{}) string {
+	*params = append(*params, val)
+	if db.dialect.ParamStyle() != NamedParams {
+		return "?"
+	}
+	paramName := fmt.Sprintf("insert%d", len(*params))
+	*paramNames = append(*paramNames, paramName)
+	return fmt.Sprintf("@%s", paramName)
+}
+
+// columns are inserted in alphabetical order so the generated query is the same for the same values
+func (db *builder) Insert(values map[string]interface{}, escape bool) {
+	columns, vals := sortedValues(values)
+	db.InsertOrdered(columns, vals, escape)
+}
+
+// inserts a row with the columns in the given order, vals should match the order of columns
+func (db *builder) InsertOrdered(columns []string, vals []interface{}, escape bool) {
+	db.valuesError = checkValueCount("insert", columns, vals)
+	if db.valuesError != nil {
+		return
+	}
+	var params []interface{}
+	paramNames := []string{}
+	insertColumns := []string{}
+	insertValues := []string{}
+	for i, key := range columns {
+		val := vals[i]
+		insertColumns = append(insertColumns, db.checkReserved(key))
+		if escape {
+			insertValues = append(insertValues, db.addValueParam(&params, &paramNames, val))
+		} else {
+			switch v := val.(type) {
+			case string:
+				insertValues = append(insertValues, v)
+			}
+		}
+	}
+	db.params = params
+	db.paramNames = paramNames
+	db.insertColumns = insertColumns
+	db.insertValues = insertValues
+	db.insertSelect = ""
+	db.insertedStructs = nil
+	db.upsert = false
+}
+
+func (db *builder) InsertMulti(columns []string, rows [][]interface{}, escape bool) {
+	var params []interface{}
+	paramNames := []string{}
+	insertColumns := []string{}
+	multiInsertValues := [][]string{}
+	for _, col := range columns {
+		insertColumns = append(insertColumns, db.checkReserved(col))
+	}
+	for _, row := range rows {
+		rowInsertValues := []string{}
+		for _, val := range row {
+			if escape {
+				rowInsertValues = append(rowInsertValues, db.addValueParam(&params, &paramNames, val))
+			} else {
+				switch v := val.(type) {
+				case string:
+					rowInsertValues = append(rowInsertValues, v)
+				}
+			}
+		}
+		multiInsertValues = append(multiInsertValues, rowInsertValues)
+	}
+	db.insertColumns = insertColumns
+	db.params = params
+	db.paramNames = paramNames
+	db.multiInsertValues = multiInsertValues
+	db.insertSelect = ""
+	db.insertedStructs = nil
+	db.upsert = false
+	db.valuesError = nil
+}
+
+// inserts the rows selected by source, the selected columns should match the order of columns
+func (db *builder) InsertFrom(columns []string, source DB) {
+	insertColumns := []string{}
+	for _, col := range columns {
+		insertColumns = append(insertColumns, db.checkReserved(col))
+	}
+	db.insertColumns = insertColumns
+	db.insertSelect = source.GenerateSelect()
+	db.params = source.getParams()
+	db.paramNames = source.getParamNames()
+	db.insertValues = nil
+	db.multiInsertValues = nil
+	db.insertedStructs = nil
+	db.upsert = false
+	db.valuesError = nil
+}
+
+// inserts a row, or updates updateCols of the existing row if it conflicts on conflictCols,
+// if updateCols is empty every column that isn't a conflict column is updated
+func (db *builder) Upsert(values map[string]interface{}, conflictCols []string, updateCols []string) {
+	columns, vals := sortedValues(values)
+	db.UpsertMulti(columns, [][]interface{}{vals}, conflictCols, updateCols)
+}
+
+func (db *builder) UpsertMulti(columns []string, rows [][]interface{}, conflictCols []string, updateCols []string) {
+	db.InsertMulti(columns, rows, true)
+	if len(updateCols) == 0 {
+		for _, col := range columns {
+			isConflict := false
+			for _, conflictCol := range conflictCols {
+				if col == conflictCol {
+					isConflict = true
+					break
+				}
+			}
+			if !isConflict {
+				updateCols = append(updateCols, col)
+			}
+		}
+	}
+	db.upsert = true
+	db.upsertConflict = []string{}
+	for _, col := range conflictCols {
+		db.upsertConflict = append(db.upsertConflict, db.checkReserved(col))
+	}
+	db.upsertUpdate = []string{}
+	for _, col := range updateCols {
+		db.upsertUpdate = append(db.upsertUpdate, db.checkReserved(col))
+	}
+}
+
+// columns are updated in alphabetical order so the generated query is the same for the same values
+func (db *builder) Update(values map[string]interface{}, escape bool) {
+	columns, vals := sortedValues(values)
+	db.UpdateOrdered(columns, vals, escape)
+}
+
+// updates the columns in the given order, vals should match the order of columns
+func (db *builder) UpdateOrdered(columns []string, vals []interface{}, escape bool) {
+	db.valuesError = checkValueCount("update", columns, vals)
+	if db.valuesError != nil {
+		return
+	}
+	var params []interface{}
+	paramNames := []string{}
+	updateStrings := []string{}
+
+	for i, key := range columns {
+		val := vals[i]
+		if escape {
+			updateStrings = append(updateStrings, fmt.Sprintf("%s = %s", db.checkReserved(key), db.addValueParam(&params, &paramNames, val)))
+		} else {
+			switch v := val.(type) {
+			case string:
+				updateStrings = append(updateStrings, fmt.Sprintf("%s = %s", db.checkReserved(key), v))
+			}
+		}
+	}
+	db.params = params
+	db.paramNames = paramNames
+	db.updateValues = updateStrings
+}
+
+// join conditions get their own parameter prefix so named parameters don't clash with the where conditions
+func (db *builder) newJoinQuery() Query {
+	q := Query{dialect: db.dialect}
+	if db.dialect.ParamStyle() == NamedParams {
+		q.SetParamPrefix(fmt.Sprintf("%sJoin%d_", db.query.paramPrefix, len(db.joins)+1))
+	}
+	return q
+}
+
+// the name of a joined table followed by its alias if it has one
+func (db *builder) joinTableName(tableName string, alias string) string {
+	if alias == "" {
+		return db.checkReserved(tableName)
+	}
+	return fmt.Sprintf("%s %s", db.checkReserved(tableName), db.checkReserved(alias))
+}
+
+func (db *builder) addTableJoin(joinType string, tableName string, alias string, primaryKey string, foreignKey string) {
+	q := db.newJoinQuery()
+	q.On(db.checkReserved(primaryKey), "=", db.checkReserved(foreignKey), false)
+	db.joins = append(db.joins, join{
+		Type:  joinType,
+		Table: db.joinTableName(tableName, alias),
+		Query: q})
+}
+
+func (db *builder) JoinTable(tableName string, primaryKey string, foreignKey string) {
+	db.addTableJoin("JOIN", tableName, "", primaryKey, foreignKey)
+}
+
+func (db *builder) LeftJoinTable(tableName string, primaryKey string, foreignKey string) {
+	db.addTableJoin("LEFT JOIN", tableName, "", primaryKey, foreignKey)
+}
+
+func (db *builder) RightJoinTable(tableName string, primaryKey string, foreignKey string) {
+	db.addTableJoin("RIGHT JOIN", tableName, "", primaryKey, foreignKey)
+}
+
+func (db *builder) FullJoinTable(tableName string, primaryKey string, foreignKey string) {
+	db.addTableJoin("FULL OUTER JOIN", tableName, "", primaryKey, foreignKey)
+}
+
+func (db *builder) JoinTableAs(tableName string, alias string, primaryKey string, foreignKey string) {
+	db.addTableJoin("JOIN", tableName, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) LeftJoinTableAs(tableName string, alias string, primaryKey string, foreignKey string) {
+	db.addTableJoin("LEFT JOIN", tableName, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) RightJoinTableAs(tableName string, alias string, primaryKey string, foreignKey string) {
+	db.addTableJoin("RIGHT JOIN", tableName, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) FullJoinTableAs(tableName string, alias string, primaryKey string, foreignKey string) {
+	db.addTableJoin("FULL OUTER JOIN", tableName, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) addSubJoin(joinType string, subSql DB, alias string, primaryKey string, foreignKey string) {
+	q := db.newJoinQuery()
+	q.On(db.checkReserved(primaryKey), "=", db.checkReserved(foreignKey), false)
+	tableName := fmt.Sprintf("(%s) %s", subSql.GenerateSelect(), db.checkReserved(alias))
+	db.joins = append(db.joins, join{
+		Type:       joinType,
+		Table:      tableName,
+		Query:      q,
+		Params:     subSql.getParams(),
+		ParamNames: subSql.getParamNames(),
+	})
+}
+
+func (db *builder) JoinSub(subSql DB, alias string, primaryKey string, foreignKey string) {
+	db.addSubJoin("JOIN", subSql, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) LeftJoinSub(subSql DB, alias string, primaryKey string, foreignKey string) {
+	db.addSubJoin("LEFT JOIN", subSql, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) RightJoinSub(subSql DB, alias string, primaryKey string, foreignKey string) {
+	db.addSubJoin("RIGHT JOIN", subSql, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) FullJoinSub(subSql DB, alias string, primaryKey string, foreignKey string) {
+	db.addSubJoin("FULL OUTER JOIN", subSql, alias, primaryKey, foreignKey)
+}
+
+func (db *builder) addQueryTableJoin(joinType string, tableName string, alias string, queryFunc queryFunc) {
+	q := db.newJoinQuery()
+	queryFunc(&q)
+	db.joins = append(db.joins, join{
+		Type:  joinType,
+		Table: db.joinTableName(tableName, alias),
+		Query: q})
+}
+
+func (db *builder) JoinTableQuery(tableName string, queryFunc queryFunc) {
+	db.addQueryTableJoin("JOIN", tableName, "", queryFunc)
+}
+
+func (db *builder) LeftJoinTableQuery(tableName string, queryFunc queryFunc) {
+	db.addQueryTableJoin("LEFT JOIN", tableName, "", queryFunc)
+}
+
+func (db *builder) RightJoinTableQuery(tableName string, queryFunc queryFunc) {
+	db.addQueryTableJoin("RIGHT JOIN", tableName, "", queryFunc)
+}
+
+func (db *builder) FullJoinTableQuery(tableName string, queryFunc queryFunc) {
+	db.addQueryTableJoin("FULL OUTER JOIN", tableName, "", queryFunc)
+}
+
+func (db *builder) JoinTableQueryAs(tableName string, alias string, queryFunc queryFunc) {
+	db.addQueryTableJoin("JOIN", tableName, alias, queryFunc)
+}
+
+func (db *builder) LeftJoinTableQueryAs(tableName string, alias string, queryFunc queryFunc) {
+	db.addQueryTableJoin("LEFT JOIN", tableName, alias, queryFunc)
+}
+
+func (db *builder) RightJoinTableQueryAs(tableName string, alias string, queryFunc queryFunc) {
+	db.addQueryTableJoin("RIGHT JOIN", tableName, alias, queryFunc)
+}
+
+func (db *builder) FullJoinTableQueryAs(tableName string, alias string, queryFunc queryFunc) {
+	db.addQueryTableJoin("FULL OUTER JOIN", tableName, alias, queryFunc)
+}
+
+func (db *builder) addQuerySubJoin(joinType string, subSql DB, alias string, queryFunc queryFunc) {
+	q := db.newJoinQuery()
+	queryFunc(&q)
+	tableName := fmt.Sprintf("(%s) %s", subSql.GenerateSelect(), db.checkReserved(alias))
+	db.joins = append(db.joins, join{
+		Type:       joinType,
+		Table:      tableName,
+		Query:      q,
+		Params:     subSql.getParams(),
+		ParamNames: subSql.getParamNames(),
+	})
+}
+
+func (db *builder) JoinSubQuery(subSql DB, alias string, queryFunc queryFunc) {
+	db.addQuerySubJoin("JOIN", subSql, alias, queryFunc)
+}
+
+func (db *builder) LeftJoinSubQuery(subSql DB, alias string, queryFunc queryFunc) {
+	db.addQuerySubJoin("LEFT JOIN", subSql, alias, queryFunc)
+}
+
+func (db *builder) RightJoinSubQuery(subSql DB, alias string, queryFunc queryFunc) {
+	db.addQuerySubJoin("RIGHT JOIN", subSql, alias, queryFunc)
+}
+
+func (db *builder) FullJoinSubQuery(subSql DB, alias string, queryFunc queryFunc) {
+	db.addQuerySubJoin("FULL OUTER JOIN", subSql, alias, queryFunc)
+}
+
+// cross joins have no conditions so every row is joined to every row of the other table
+func (db *builder) CrossJoin(tableName string) {
+	db.joins = append(db.joins, join{
+		Type:  "CROSS JOIN",
+		Table: db.joinTableName(tableName, ""),
+		Query: db.newJoinQuery()})
+}
+
+func (db *builder) CrossJoinAs(tableName string, alias string) {
+	db.joins = append(db.joins, join{
+		Type:  "CROSS JOIN",
+		Table: db.joinTableName(tableName, alias),
+		Query: db.newJoinQuery()})
+}
+
+// named parameters of the sub query are renamed so they don't clash with the parameters of this query
+func (db *builder) addApplyJoin(outer bool, subSql DB, alias string) {
+	joinType, needsOn, _ := lateralJoin(db.dialect, outer)
+	q := db.newJoinQuery()
+	if needsOn {
+		q.On("1", "=", "1", false)
+	}
+	subQuery := subSql.GenerateSelect()
+	subParamNames := subSql.getParamNames()
+	if db.dialect.ParamStyle() == NamedParams {
+		subQuery, subParamNames = renameParams(subQuery, subParamNames, fmt.Sprintf("%sApply%d_", db.query.paramPrefix, len(db.joins)+1))
+	}
+	db.joins = append(db.joins, join{
+		Type:       joinType,
+		Table:      fmt.Sprintf("(%s) %s", subQuery, db.checkReserved(alias)),
+		Query:      q,
+		Params:     subSql.getParams(),
+		ParamNames: subParamNames,
+		Apply:      true,
+	})
+}
+
+// joins a sub query which can refer to the columns of the tables before it, run once for each row,
+// rendered as CROSS APPLY on SQL Server and CROSS JOIN LATERAL on databases that support LATERAL
+func (db *builder) CrossApply(subSql DB, alias string) {
+	db.addApplyJoin(false, subSql, alias)
+}
+
+// like CrossApply but keeps rows where the sub query returns nothing, OUTER APPLY on SQL Server
+// and LEFT JOIN LATERAL on databases that support LATERAL
+func (db *builder) OuterApply(subSql DB, alias string) {
+	db.addApplyJoin(true, subSql, alias)
+}
+
+func (db *builder) Where(field string, comparator string, value interface{}, escape bool) {
+	db.query.Where(db.checkReserved(field), comparator, value, escape)
+}
+
+func (db *builder) WhereNull(field string) {
+	db.query.WhereNull(db.checkReserved(field))
+}
+
+func (db *builder) WhereNotNull(field string) {
+	db.query.WhereNotNull(db.checkReserved(field))
+}
+
+func (db *builder) WhereInList(field string, values []interface{}, escape bool) {
+	db.query.WhereInList(db.checkReserved(field), values, escape)
+}
+
+func (db *builder) WhereNotInList(field string, values []interface{}, escape bool) {
+	db.query.WhereNotInList(db.checkReserved(field), values, escape)
+}
+
+func (db *builder) WhereInSub(field string, subSql DB) {
+	db.query.WhereInSub(db.checkReserved(field), subSql)
+}
+
+func (db *builder) WhereNotInSub(field string, subSql DB) {
+	db.query.WhereNotInSub(db.checkReserved(field), subSql)
+}
+
+func (db *builder) OrWhere(field string, comparator string, value interface{}, escape bool) {
+	db.query.OrWhere(db.checkReserved(field), comparator, value, escape)
+}
+
+func (db *builder) WhereGroup(groupFunc queryFunc) {
+	db.query.WhereGroup(groupFunc)
+}
+
+func (db *builder) OrWhereGroup(groupFunc queryFunc) {
+	db.query.OrWhereGroup(groupFunc)
+}
+
+func (db *builder) WhereBetween(field string, from interface{}, to interface{}) {
+	db.query.WhereBetween(db.checkReserved(field), from, to)
+}
+
+func (db *builder) WhereNotBetween(field string, from interface{}, to interface{}) {
+	db.query.WhereNotBetween(db.checkReserved(field), from, to)
+}
+
+func (db *builder) WhereLike(field string, pattern string) {
+	db.query.WhereLike(db.checkReserved(field), pattern)
+}
+
+func (db *builder) WhereILike(field string, pattern string) {
+	db.query.WhereILike(db.checkReserved(field), pattern)
+}
+
+func (db *builder) WhereExists(subSql DB) {
+	db.query.WhereExists(subSql)
+}
+
+func (db *builder) WhereNotExists(subSql DB) {
+	db.query.WhereNotExists(subSql)
+}
+
+func (db *builder) WhereColumn(field string, comparator string, otherField string) {
+	db.query.WhereColumn(db.checkReserved(field), comparator, db.checkReserved(otherField))
+}
+
+func (db *builder) WhereDate(field string, comparator string, value interface{}) {
+	db.query.WhereDate(db.checkReserved(field), comparator, value)
+}
+
+func (db *builder) WhereYear(field string, comparator string, year int) {
+	db.query.WhereYear(db.checkReserved(field), comparator, year)
+}
+
+func (db *builder) WhereMonth(field string, comparator string, month int) {
+	db.query.WhereMonth(db.checkReserved(field), comparator, month)
+}
+
+func (db *builder) Or() {
+	db.query.Or()
+}
+func (db *builder) And() {
+	db.query.And()
+}
+
+func (db *builder) OpenBracket() {
+	db.query.OpenBracket()
+}
+
+func (db *builder) CloseBracket() {
+	db.query.CloseBracket()
+}
+
+func (db *builder) LimitBy(number int) {
+	db.limitBy = number
+}
+
+func (db *builder) OffsetBy(number int) {
+	db.offsetBy = number
+}
+
+func (db *builder) OrderBy(field string, direction string) {
+	direction = strings.ToUpper(direction)
+	if direction == "ASC" || direction == "DESC" {
+		db.ordering = append(db.ordering, orderBy{
+			Field:     db.checkReserved(field),
+			Direction: direction,
+		})
+	}
+
+}
+
+func (db *builder) GroupBy(field ...string) {
+	for _, f := range field {
+		db.groupColumns = append(db.groupColumns, db.checkReserved(f))
+	}
+
+}
+
+// adds a common table expression which can be used as a table by the query
+func (db *builder) With(name string, sub DB) {
+	db.ctes = append(db.ctes, cte{Name: name, Query: sub})
+}
+
+// adds a recursive common table expression, the anchor select is combined by UNION ALL with the recursive select
+// which selects from the expression's name
+func (db *builder) WithRecursive(name string, anchor DB, recursive DB) {
+	db.ctes = append(db.ctes, cte{Name: name, Query: anchor, RecursiveQuery: recursive})
+}
+
+// renders the WITH clause and its parameters, SQL Server parameter names are prefixed per expression so they don't clash
+func (db *builder) generateWith() (string, []interface{}, []string) {
+	var params []interface{}
+	paramNames := []string{}
+	if len(db.ctes) == 0 {
+		return "", params, paramNames
+	}
+	keyword := "WITH"
+	expressions := []string{}
+	addSelect := func(sub DB, prefix string) string {
+		subQuery := sub.GenerateSelect()
+		subParamNames := sub.getParamNames()
+		if db.dialect.ParamStyle() == NamedParams {
+			subQuery, subParamNames = renameParams(subQuery, subParamNames, prefix)
+		}
+		params = append(params, sub.getParams()...)
+		paramNames = append(paramNames, subParamNames...)
+		return subQuery
+	}
+	for i, c := range db.ctes {
+		prefix := fmt.Sprintf("%sWith%d_", db.query.paramPrefix, i+1)
+		expression := addSelect(c.Query, prefix)
+		if c.RecursiveQuery != nil {
+			keyword = withRecursiveKeyword(db.dialect)
+			expression += " UNION ALL " + addSelect(c.RecursiveQuery, prefix+"Recursive_")
+		}
+		expressions = append(expressions, fmt.Sprintf("%s AS (%s)", db.checkReserved(c.Name), expression))
+	}
+	return fmt.Sprintf("%s %s ", keyword, strings.Join(expressions, ", ")), params, paramNames
+}
+
+// combines the results of another select with this one, any ordering and limits set on this query
+// apply to the combined results so shouldn't be set on the other select
+func (db *builder) Union(other DB) {
+	db.compounds = append(db.compounds, compound{Type: "UNION", Query: other})
+}
+
+func (db *builder) UnionAll(other DB) {
+	db.compounds = append(db.compounds, compound{Type: "UNION ALL", Query: other})
+}
+
+func (db *builder) Intersect(other DB) {
+	db.compounds = append(db.compounds, compound{Type: "INTERSECT", Query: other})
+}
+
+func (db *builder) Except(other DB) {
+	db.compounds = append(db.compounds, compound{Type: "EXCEPT", Query: other})
+}
+
+// having conditions filter the grouped results and are combined with AND unless added with OrHaving
+func (db *builder) Having(field string, comparator string, value interface{}, escape bool) {
+	db.having.Where(db.checkReserved(field), comparator, value, escape)
+}
+
+func (db *builder) OrHaving(field string, comparator string, value interface{}, escape bool) {
+	db.having.OrWhere(db.checkReserved(field), comparator, value, escape)
+}
+
+func (db *builder) HavingIn(field string, values []interface{}, escape bool) {
+	db.having.WhereInList(db.checkReserved(field), values, escape)
+}
+
+func (db *builder) HavingNotIn(field string, values []interface{}, escape bool) {
+	db.having.WhereNotInList(db.checkReserved(field), values, escape)
+}
+
+func (db *builder) OpenHavingBracket() {
+	db.having.OpenBracket()
+}
+
+// opens a bracket joined to the previous condition with OR, conditions inside the bracket are joined with AND
+func (db *builder) OrOpenHavingBracket() {
+	db.having.Or()
+	db.having.OpenBracket()
+	db.having.And()
+}
+
+func (db *builder) CloseHavingBracket() {
+	db.having.CloseBracket()
+}
+
+// reports whether the query has full joins which the dialect can't run, see generateFullJoinSelect
+func (db *builder) emulatesFullJoin() bool {
+	if supportsFullJoin(db.dialect) {
+		return false
+	}
+	for _, j := range db.joins {
+		if j.Type == "FULL OUTER JOIN" {
+			return true
+		}
+	}
+	return false
+}
+
+// renders the joins, full joins are rendered as fullJoinType so they can be emulated
+func (db *builder) generateJoins(fullJoinType string) (string, []interface{}, []string) {
+	query := ""
+	var params []interface{}
+	paramNames := []string{}
+	for _, j := range db.joins {
+		params = append(params, j.Params...)
+		paramNames = append(paramNames, j.ParamNames...)
+		joinType := j.Type
+		if joinType == "FULL OUTER JOIN" {
+			joinType = fullJoinType
+		}
+		query += fmt.Sprintf(" %s %s ", joinType, j.Table)
+		if len(j.Query.wheres) > 0 {
+			whereString, jParams, jParamNames := j.Query.ApplyWheres()
+			query += fmt.Sprintf(" ON %s ", whereString)
+			params = append(params, jParams...)
+			paramNames = append(paramNames, jParamNames...)
+		}
+	}
+	return query, params, paramNames
+}
+
+// renders the select up to and including the HAVING conditions
+func (db *builder) generateSelectBody() (string, []interface{}, []string) {
+	query := "SELECT "
+	query += strings.Join(db.cols, ",")
+	query += " FROM "
+	query += fmt.Sprintf(" %s ", db.table)
+	if tableHint, _ := db.lockClause(); tableHint != "" {
+		query += fmt.Sprintf(" %s ", tableHint)
+	}
+
+	joinString, params, paramNames := db.generateJoins("FULL OUTER JOIN")
+	query += joinString
+
+	if len(db.query.wheres) > 0 {
+		whereString, newParams, newParamNames := db.query.ApplyWheres()
+		params = append(params, newParams...)
+		paramNames = append(paramNames, newParamNames...)
+		query += " WHERE " + whereString
+	}
+
+	if len(db.groupColumns) > 0 {
+		query += fmt.Sprintf(" GROUP BY %s", strings.Join(db.groupColumns, ","))
+
+	}
+
+	if len(db.having.wheres) > 0 {
+		havingString, newParams, newParamNames := db.having.ApplyWheres()
+		params = append(params, newParams...)
+		paramNames = append(paramNames, newParamNames...)
+		query += " HAVING " + havingString
+	}
+	return query, params, paramNames
+}
+
+func (db *builder) GenerateSelect() string {
+	query, params, paramNames := db.generateWith()
+	ordering := db.ordering
+	if db.emulatesFullJoin() {
+		selectString, newParams, newParamNames, fullJoinOrdering := db.generateFullJoinSelect()
+		query += selectString
+		params = append(params, newParams...)
+		paramNames = append(paramNames, newParamNames...)
+		ordering = fullJoinOrdering
+	} else {
+		selectString, newParams, newParamNames := db.generateSelectBody()
+		query += selectString
+		params = append(params, newParams...)
+		paramNames = append(paramNames, newParamNames...)
+	}
+
+	for i, c := range db.compounds {
+		compoundQuery := c.Query.GenerateSelect()
+		compoundParamNames := c.Query.getParamNames()
+		if db.dialect.ParamStyle() == NamedParams {
+			compoundQuery, compoundParamNames = renameParams(compoundQuery, compoundParamNames, fmt.Sprintf("%sCompound%d_", db.query.paramPrefix, i+1))
+		}
+		query += fmt.Sprintf(" %s %s ", c.Type, compoundQuery)
+		params = append(params, c.Query.getParams()...)
+		paramNames = append(paramNames, compoundParamNames...)
+	}
+
+	if len(ordering) > 0 {
+		query += " ORDER BY "
+		orderStrings := []string{}
+		for _, o := range ordering {
+			orderStrings = append(orderStrings, fmt.Sprintf("%s %s", o.Field, o.Direction))
+		}
+		query += strings.Join(orderStrings, ", ")
+	}
+
+	query += db.dialect.LimitOffset(db.limitBy, db.offsetBy, len(db.ordering) > 0)
+
+	if _, suffix := db.lockClause(); suffix != "" {
+		query += fmt.Sprintf(" %s ", suffix)
+	}
+
+	db.params = params
+	db.paramNames = paramNames
+	return query
+}
+
+// returns an empty string when the dialect doesn't support upserts
+func (db *builder) GenerateUpsert() string {
+	d, ok := db.dialect.(UpsertDialect)
+	if !ok {
+		return ""
+	}
+	return d.Upsert(db.table, db.insertColumns, db.multiInsertValues, db.upsertConflict, db.upsertUpdate)
+}
+
+func (db *builder) GenerateInsert() string {
+	output, returning := db.generateReturning(false)
+	query := fmt.Sprintf("INSERT INTO %s ", db.table)
+	if db.insertSelect != "" {
+		return query + fmt.Sprintf(" (%s)%s %s%s", strings.Join(db.insertColumns, ","), output, db.insertSelect, returning)
+	}
+	query += fmt.Sprintf(" (%s)%s VALUES ", strings.Join(db.insertColumns, ","), output)
+	if len(db.insertValues) > 0 {
+		query += fmt.Sprintf(" (%s) ", strings.Join(db.insertValues, ","))
+	} else if len(db.multiInsertValues) > 0 {
+		insertRows := []string{}
+		for _, row := range db.multiInsertValues {
+			insertRows = append(insertRows, fmt.Sprintf(" (%s) ", strings.Join(row, ",")))
+		}
+		query += strings.Join(insertRows, ",")
+	}
+	return query + returning
+}
+
+// adds the WITH clause to the start of an update or delete, its parameters go before the values already set
+func (db *builder) prependWith(query string) string {
+	withString, params, paramNames := db.generateWith()
+	if withString == "" {
+		return query
+	}
+	db.params = append(params, db.params...)
+	db.paramNames = append(paramNames, db.paramNames...)
+	return withString + query
+}
+
+// sets the columns of the inserted, updated or deleted rows returned by SaveReturning and DeleteReturning
+func (db *builder) Returning(cols ...string) {
+	db.returning = []string{}
+	for _, col := range cols {
+		if col == "*" {
+			db.returning = append(db.returning, col)
+		} else {
+			db.returning = append(db.returning, db.checkReserved(col))
+		}
+	}
+}
+
+// renders the Returning columns as either an OUTPUT clause in the middle of the query or a RETURNING clause at the end
+func (db *builder) generateReturning(deleted bool) (string, string) {
+	if len(db.returning) == 0 {
+		return "", ""
+	}
+	clause, output := returningClause(db.returning)
+	if d, ok := db.dialect.(ReturningDialect); ok {
+		clause, output = d.ReturningClause(db.returning, deleted)
+	}
+	if output {
+		return " " + clause + " ", ""
+	}
+	return "", " " + clause
+}
+
+// renders the conditions of an update or delete
+func (db *builder) generateWriteWhere() (string, []interface{}, []string) {
+	var params []interface{}
+	paramNames := []string{}
+	if len(db.query.wheres) == 0 {
+		return "", params, paramNames
+	}
+	whereStr, params, paramNames := db.query.ApplyWheres()
+	return fmt.Sprintf(" WHERE %s ", whereStr), params, paramNames
+}
+
+// the generated query is only run when the dialect implements JoinedWriteDialect, see checkJoinedWrite
+func (db *builder) joinedWriteStyle() (JoinedWriteStyle, string) {
+	if d, ok := db.dialect.(JoinedWriteDialect); ok {
+		return d.JoinedWriteStyle()
+	}
+	return JoinedWriteInline, ""
+}
+
+func (db *builder) checkJoinedWrite() error {
+	if _, ok := db.dialect.(JoinedWriteDialect); !ok && len(db.joins) > 0 {
+		return errors.New("updates and deletes with joins aren't supported by this database")
+	}
+	return nil
+}
+
+func (db *builder) GenerateUpdate() string {
+	output, returning := db.generateReturning(false)
+	setString := strings.Join(db.updateValues, ",") + output
+	params, paramNames := db.params, db.paramNames
+	whereString, whereParams, whereParamNames := db.generateWriteWhere()
+	if len(db.joins) == 0 {
+		db.params = append(params, whereParams...)
+		db.paramNames = append(paramNames, whereParamNames...)
+		return db.prependWith(fmt.Sprintf("UPDATE %s SET %s%s%s", db.table, setString, whereString, returning))
+	}
+
+	joinString, joinParams, joinParamNames := db.generateJoins("FULL OUTER JOIN")
+	query := ""
+	switch style, rowId := db.joinedWriteStyle(); style {
+	case JoinedWriteRowId:
+		query = fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (SELECT %s.%s FROM %s %s%s)", db.table, setString, rowId, db.table, rowId, db.table, joinString, whereString)
+		params = append(params, joinParams...)
+		paramNames = append(paramNames, joinParamNames...)
+	case JoinedWriteFrom:
+		query = fmt.Sprintf("UPDATE %s SET %s FROM %s %s%s", db.table, setString, db.table, joinString, whereString)
+		params = append(joinParams, params...)
+		paramNames = append(joinParamNames, paramNames...)
+	default:
+		query = fmt.Sprintf("UPDATE %s %s SET %s%s", db.table, joinString, setString, whereString)
+		params = append(joinParams, params...)
+		paramNames = append(joinParamNames, paramNames...)
+	}
+	db.params = append(params, whereParams...)
+	db.paramNames = append(paramNames, whereParamNames...)
+	return db.prependWith(query + returning)
+}
+
+func (db *builder) GenerateDelete() string {
+	output, returning := db.generateReturning(true)
+	whereString, whereParams, whereParamNames := db.generateWriteWhere()
+	query := fmt.Sprintf("DELETE FROM %s%s %s", db.table, output, whereString)
+	if len(db.joins) > 0 {
+		joinString, joinParams, joinParamNames := db.generateJoins("FULL OUTER JOIN")
+		if style, rowId := db.joinedWriteStyle(); style == JoinedWriteRowId {
+			query = fmt.Sprintf("DELETE FROM %s%s WHERE %s IN (SELECT %s.%s FROM %s %s%s)", db.table, output, rowId, db.table, rowId, db.table, joinString, whereString)
+		} else {
+			query = fmt.Sprintf("DELETE %s%s FROM %s %s%s", db.table, output, db.table, joinString, whereString)
+		}
+		db.params = append(db.params, joinParams...)
+		db.paramNames = append(db.paramNames, joinParamNames...)
+	}
+	db.params = append(db.params, whereParams...)
+	db.paramNames = append(db.paramNames, whereParamNames...)
+	return db.prependWith(query + returning)
+}
+
+func (db *builder) saveInsert(ctx context.Context) (sql.Result, error) {
+	query := db.GenerateInsert()
+	sqlResult := insertResult{}
+
+	var affectedRows int64
+	affectedRows = 1
+	if len(db.multiInsertValues) > 0 {
+		affectedRows = int64(len(db.multiInsertValues))
+	}
+
+	switch db.dialect.InsertIdStrategy() {
+	case InsertIdLast:
+		res, err := db.executeNonQuery(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		//work back from the last inserted id to the first to match the other databases
+		sqlResult.rowsAffected, sqlResult.err = res.RowsAffected()
+		if sqlResult.err != nil {
+			return &sqlResult, sqlResult.err
+		}
+		sqlResult.lastInsertId, sqlResult.err = res.LastInsertId()
+		if sqlResult.err != nil {
+			return &sqlResult, sqlResult.err
+		}
+		sqlResult.lastInsertId -= (sqlResult.rowsAffected - 1)
+	case InsertIdReturning:
+		fetchRes, close, err := db.executeQuery(ctx, query+" RETURNING id")
+		if err != nil {
+			sqlResult.err = err
+			return &sqlResult, err
+		}
+		defer close()
+		var returnedRows int64
+		for fetchRes.Next() {
+			var id int64
+			fetchRes.Scan(&id)
+			if returnedRows == 0 || id < sqlResult.lastInsertId {
+				sqlResult.lastInsertId = id
+			}
+			returnedRows++
+		}
+		sqlResult.rowsAffected = returnedRows
+	case InsertIdScopeIdentity:
+		fetchRes, close, err := db.executeQuery(ctx, query+"; select isNull(SCOPE_IDENTITY(), -1);")
+		if err != nil {
+			sqlResult.err = err
+			return &sqlResult, err
+		}
+		defer close()
+		var lastId int64
+		for fetchRes.Next() {
+			fetchRes.Scan(&lastId)
+		}
+		lastId -= (affectedRows - 1)
+		sqlResult.lastInsertId = lastId
+		sqlResult.rowsAffected = affectedRows
+	default:
+		return db.executeNonQuery(ctx, query)
+	}
+	return &sqlResult, nil
+}
+
+// locks the selected rows until the transaction ends so other transactions can't lock or update them
+func (db *builder) LockForUpdate() {
+	db.lockMode = LockUpdate
+}
+
+// locks the selected rows against updates until the transaction ends while still letting other transactions read them
+func (db *builder) SharedLock() {
+	db.lockMode = LockShared
+}
+
+// leaves rows locked by other transactions out of the results, the rows are locked for update if no lock has been set
+func (db *builder) SkipLocked() {
+	db.lockWait = LockSkipLocked
+	if db.lockMode == LockNone {
+		db.lockMode = LockUpdate
+	}
+}
+
+// errors instead of waiting when rows are locked by another transaction, the rows are locked for update if no lock has been set
+func (db *builder) NoWait() {
+	db.lockWait = LockNoWait
+	if db.lockMode == LockNone {
+		db.lockMode = LockUpdate
+	}
+}
+
+func (db *builder) lockClause() (string, string) {
+	if db.lockMode == LockNone {
+		return "", ""
+	}
+	if d, ok := db.dialect.(LockDialect); ok {
+		return d.LockClause(db.lockMode, db.lockWait)
+	}
+	return forLockSuffix(db.lockMode, db.lockWait)
+}
+
+// checks the conditions of the query can generate valid SQL, called before the query is run
+func (db *builder) Validate() error {
+	if err := db.query.checkBrackets(); err != nil {
+		return fmt.Errorf("where conditions: %w", err)
+	}
+	if err := db.having.checkBrackets(); err != nil {
+		return fmt.Errorf("having conditions: %w", err)
+	}
+	for i, j := range db.joins {
+		if err := j.Query.checkBrackets(); err != nil {
+			return fmt.Errorf("join %d conditions: %w", i+1, err)
+		}
+		if _, _, supported := lateralJoin(db.dialect, false); j.Apply && !supported {
+			return errors.New("CrossApply and OuterApply aren't supported by this database")
+		}
+	}
+	if db.valuesError != nil {
+		return db.valuesError
+	}
+	if db.upsert {
+		d, ok := db.dialect.(UpsertDialect)
+		if !ok {
+			return errors.New("upserts aren't supported by this database")
+		}
+		if d.UpsertNeedsConflictColumns() && len(db.upsertConflict) == 0 {
+			return errors.New("upserts need conflict columns on this database")
+		}
+	}
+	if db.emulatesFullJoin() {
+		if db.lockMode != LockNone {
+			return errors.New("row locks can't be used with emulated full joins")
+		}
+		for _, col := range db.cols {
+			if col == "*" || strings.HasSuffix(col, ".*") {
+				return errors.New("emulated full joins can't select every column with *, the columns should be listed")
+			}
+		}
+	}
+	if db.lockMode != LockNone && db.tx == nil {
+		return errors.New("row locks can only be used within a transaction")
+	}
+	return nil
+}
+
+func (db *builder) Save() (sql.Result, error) {
+	return db.SaveContext(context.Background())
+}
+
+func (db *builder) SaveContext(ctx context.Context) (sql.Result, error) {
+	if err := db.Validate(); err != nil {
+		return nil, err
+	}
+	if db.upsert {
+		return db.executeNonQuery(ctx, db.GenerateUpsert())
+	} else if db.insertSelect != "" {
+		//the ids of rows inserted from a select aren't known so only the affected rows are returned
+		return db.executeNonQuery(ctx, db.GenerateInsert())
+	} else if len(db.insertValues) > 0 || len(db.multiInsertValues) > 0 {
+		//Save doesn't read the returned rows so the Returning columns are left out, keeping the inserted ids
+		returning := db.returning
+		db.returning = nil
+		res, err := db.saveInsert(ctx)
+		db.returning = returning
+		if err != nil {
+			return res, err
+		}
+		return res, db.setInsertedKeys(res)
+	} else if len(db.updateValues) > 0 {
+		if err := db.checkJoinedWrite(); err != nil {
+			return nil, err
+		}
+		return db.executeNonQuery(ctx, db.GenerateUpdate())
+	}
+	return nil, errors.New("no insert or update values to save")
+}
+
+func (db *builder) Fetch() (*sql.Rows, context.CancelFunc, error) {
+	return db.FetchContext(context.Background())
+}
+
+func (db *builder) FetchContext(ctx context.Context) (*sql.Rows, context.CancelFunc, error) {
+	if err := db.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return db.executeQuery(ctx, db.GenerateSelect())
+}
+
+func (db *builder) FetchConcurrent() (successChannel chan bool, startRowsChannel chan bool, rowChannel chan *sql.Rows, nextChannel chan bool, completeChannel chan bool, cancelChannel chan bool, errorChannel chan error) {
+	successChannel = make(chan bool)
+	startRowsChannel = make(chan bool)
+	rowChannel = make(chan *sql.Rows)
+	nextChannel = make(chan bool)
+	completeChannel = make(chan bool)
+	cancelChannel = make(chan bool)
+	errorChannel = make(chan error)
+	if err := db.Validate(); err != nil {
+		go func() {
+			errorChannel <- err
+		}()
+		return successChannel, startRowsChannel, rowChannel, nextChannel, completeChannel, cancelChannel, errorChannel
+	}
+	go db.concExecuteQuery(db.GenerateSelect(), successChannel, startRowsChannel, rowChannel, nextChannel, completeChannel, cancelChannel, errorChannel)
+	return successChannel, startRowsChannel, rowChannel, nextChannel, completeChannel, cancelChannel, errorChannel
+}
+
+// runs the insert or update and returns the Returning columns of each changed row
+func (db *builder) SaveReturning() (*sql.Rows, context.CancelFunc, error) {
+	return db.SaveReturningContext(context.Background())
+}
+
+func (db *builder) SaveReturningContext(ctx context.Context) (*sql.Rows, context.CancelFunc, error) {
+	if err := db.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if len(db.returning) == 0 {
+		return nil, nil, errors.New("no returning columns set")
+	}
+	if db.upsert {
+		return nil, nil, errors.New("returning columns aren't supported by upserts")
+	} else if db.insertSelect != "" || len(db.insertValues) > 0 || len(db.multiInsertValues) > 0 {
+		return db.executeQuery(ctx, db.GenerateInsert())
+	} else if len(db.updateValues) > 0 {
+		if err := db.checkJoinedWrite(); err != nil {
+			return nil, nil, err
+		}
+		return db.executeQuery(ctx, db.GenerateUpdate())
+	}
+	return nil, nil, errors.New("no insert or update values to save")
+}
+
+func (db *builder) Delete() (sql.Result, error) {
+	return db.DeleteContext(context.Background())
+}
+
+func (db *builder) DeleteContext(ctx context.Context) (sql.Result, error) {
+	if err := db.Validate(); err != nil {
+		return nil, err
+	}
+	if err := db.checkJoinedWrite(); err != nil {
+		return nil, err
+	}
+	return db.executeNonQuery(ctx, db.GenerateDelete())
+}
+
+// runs the delete and returns the Returning columns of each deleted row
+func (db *builder) DeleteReturning() (*sql.Rows, context.CancelFunc, error) {
+	return db.DeleteReturningContext(context.Background())
+}
+
+func (db *builder) DeleteReturningContext(ctx context.Context) (*sql.Rows, context.CancelFunc, error) {
+	if err := db.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if len(db.returning) == 0 {
+		return nil, nil, errors.New("no returning columns set")
+	}
+	if err := db.checkJoinedWrite(); err != nil {
+		return nil, nil, err
+	}
+	return db.executeQuery(ctx, db.GenerateDelete())
+}
+
+// applies the connection's default timeout unless the caller has already set a deadline
+func (db *builder) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline || db.usedConfig.Timeout < 0 {
+		return context.WithCancel(ctx)
+	}
+	timeout := db.usedConfig.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+type executor interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// returns where the query should run, a transaction if the query is part of one, otherwise the connection pool
+// or a new pool when running in parallel, close should be called once the query is finished with
+func (db *builder) executor() (con executor, close func(), err error) {
+	if db.tx != nil {
+		return db.tx, func() {}, nil
+	}
+	if db.parallel {
+		newCon, err := db.openConnection()
+		if err != nil {
+			return nil, nil, err
+		}
+		return newCon, func() { newCon.Close() }, nil
+	}
+	return openConnections[db.databaseName], func() {}, nil
+}
+
+// converts the generated query and parameters into what the dialect's driver expects
+func (db *builder) prepareQuery(query string) (string, []interface{}) {
+	switch db.dialect.ParamStyle() {
+	case DollarParams:
+		return rebindDollarParams(query), db.params
+	case NamedParams:
+		//raw queries don't have parameter names so are passed through in order
+		if len(db.paramNames) != len(db.params) {
+			return query, db.params
+		}
+		var namedParameters []interface{}
+		for i, param := range db.params {
+			namedParameters = append(namedParameters, sql.Named(db.paramNames[i], param))
+		}
+		return query, namedParameters
+	}
+	return query, db.params
+}
+
+func (db *builder) concExecuteQuery(query string, successChannel chan bool, startRowsChannel chan bool, rowChan chan *sql.Rows, nextChan chan bool, completeChan chan bool, cancelChan chan bool, errorChan chan error) {
+
+	con, closeCon, err := db.executor()
+	if err != nil {
+		fmt.Println(err)
+		errorChan <- err
+		return
+	}
+	defer closeCon()
+
+	query, params := db.prepareQuery(query)
+
+	ctx, cancelFunc := db.timeoutContext(context.Background())
+	defer cancelFunc()
+	results, err := con.QueryContext(ctx, query, params...)
+
+	if err != nil {
+		fmt.Println(err)
+		errorChan <- err
+		return
+	}
+	successChannel <- true
+	cancelled := false
+	select {
+	case <-startRowsChannel:
+		for results.Next() {
+			select {
+			case rowChan <- results:
+				<-nextChan
+			case <-cancelChan:
+				cancelled = true
+			}
+			if cancelled {
+				return
+			}
+		}
+	case <-cancelChan:
+		results.Close()
+		return
+	}
+
+	completeChan <- true
+}
+
+func (db *builder) executeQuery(ctx context.Context, query string) (*sql.Rows, context.CancelFunc, error) {
+
+	con, closeCon, err := db.executor()
+	if err != nil {
+		fmt.Println(err)
+		return nil, nil, err
+	}
+	defer closeCon()
+
+	query, params := db.prepareQuery(query)
+
+	ctx, cancelFunc := db.timeoutContext(ctx)
+	results, err := con.QueryContext(ctx, query, params...)
+
+	if err != nil {
+		fmt.Println(err)
+		defer cancelFunc()
+		return nil, nil, err
+	}
+	return results, cancelFunc, nil
+}
+
+func (db *builder) executeNonQuery(ctx context.Context, query string) (sql.Result, error) {
+	ctx, cancelFunc := db.timeoutContext(ctx)
+	defer cancelFunc()
+	con, closeCon, err := db.executor()
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	defer closeCon()
+
+	query, params := db.prepareQuery(query)
+
+	results, err := con.ExecContext(ctx, query, params...)
+
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	return results, nil
+}
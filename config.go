@@ -1,5 +1,7 @@
 package bezsql
 
+import "time"
+
 type Config struct {
 	Type     string
 	Host     string
@@ -7,4 +9,6 @@ type Config struct {
 	Database string
 	Username string
 	Password string
+	//default timeout for queries run without a context deadline, 60 seconds when not set and no timeout when negative
+	Timeout time.Duration
 }
@@ -3,6 +3,7 @@ package bezsql
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 type DB interface {
@@ -17,29 +18,61 @@ type DB interface {
 	Table(table string)
 	TableSub(subDb DB, table string)
 	RawQuery(query string, params []interface{}) (*sql.Rows, context.CancelFunc, error)
+	RawQueryContext(ctx context.Context, query string, params []interface{}) (*sql.Rows, context.CancelFunc, error)
 	RawNonQuery(query string, params []interface{}) (sql.Result, error)
+	RawNonQueryContext(ctx context.Context, query string, params []interface{}) (sql.Result, error)
 	Insert(values map[string]interface{}, escape bool)
 	InsertMulti(columns []string, rows [][]interface{}, escape bool)
+	InsertOrdered(columns []string, vals []interface{}, escape bool)
+	InsertFrom(columns []string, source DB)
+	Upsert(values map[string]interface{}, conflictCols []string, updateCols []string)
+	UpsertMulti(columns []string, rows [][]interface{}, conflictCols []string, updateCols []string)
 	Update(values map[string]interface{}, escape bool)
+	UpdateOrdered(columns []string, vals []interface{}, escape bool)
+	InsertStruct(v interface{}) error
+	InsertStructs(slice interface{}) error
+	UpdateStruct(v interface{}, onlyCols ...string) error
 	Cols(cols []string)
 	Count(col string, alias string) string
 	Sum(col string, alias string) string
 	Avg(col string, alias string) string
 	Max(col string, alias string) string
 	Min(col string, alias string) string
+	Window(fn *WindowFunction, alias string) string
 	getParams() []interface{}
 	getParamNames() []string
 	GenerateSelect() string
 	GenerateInsert() string
 	GenerateUpdate() string
+	GenerateUpsert() string
 	JoinTable(tableName string, primaryKey string, foreignKey string)
 	LeftJoinTable(tableName string, primaryKey string, foreignKey string)
+	RightJoinTable(tableName string, primaryKey string, foreignKey string)
+	FullJoinTable(tableName string, primaryKey string, foreignKey string)
+	JoinTableAs(tableName string, alias string, primaryKey string, foreignKey string)
+	LeftJoinTableAs(tableName string, alias string, primaryKey string, foreignKey string)
+	RightJoinTableAs(tableName string, alias string, primaryKey string, foreignKey string)
+	FullJoinTableAs(tableName string, alias string, primaryKey string, foreignKey string)
 	JoinSub(subSql DB, alias string, primaryKey string, foreignKey string)
 	LeftJoinSub(subSql DB, alias string, primaryKey string, foreignKey string)
+	RightJoinSub(subSql DB, alias string, primaryKey string, foreignKey string)
+	FullJoinSub(subSql DB, alias string, primaryKey string, foreignKey string)
 	JoinTableQuery(tableName string, queryFunc queryFunc)
 	LeftJoinTableQuery(tableName string, queryFunc queryFunc)
+	RightJoinTableQuery(tableName string, queryFunc queryFunc)
+	FullJoinTableQuery(tableName string, queryFunc queryFunc)
+	JoinTableQueryAs(tableName string, alias string, queryFunc queryFunc)
+	LeftJoinTableQueryAs(tableName string, alias string, queryFunc queryFunc)
+	RightJoinTableQueryAs(tableName string, alias string, queryFunc queryFunc)
+	FullJoinTableQueryAs(tableName string, alias string, queryFunc queryFunc)
 	JoinSubQuery(subSql DB, alias string, queryFunc queryFunc)
 	LeftJoinSubQuery(subSql DB, alias string, queryFunc queryFunc)
+	RightJoinSubQuery(subSql DB, alias string, queryFunc queryFunc)
+	FullJoinSubQuery(subSql DB, alias string, queryFunc queryFunc)
+	CrossJoin(tableName string)
+	CrossJoinAs(tableName string, alias string)
+	CrossApply(subSql DB, alias string)
+	OuterApply(subSql DB, alias string)
 	Where(field string, comparator string, value interface{}, escape bool)
 	WhereNull(field string)
 	WhereNotNull(field string)
@@ -47,6 +80,19 @@ type DB interface {
 	WhereNotInList(field string, values []interface{}, escape bool)
 	WhereInSub(field string, subSql DB)
 	WhereNotInSub(field string, subSql DB)
+	WhereBetween(field string, from interface{}, to interface{})
+	WhereNotBetween(field string, from interface{}, to interface{})
+	WhereLike(field string, pattern string)
+	WhereILike(field string, pattern string)
+	WhereExists(subSql DB)
+	WhereNotExists(subSql DB)
+	WhereColumn(field string, comparator string, otherField string)
+	WhereDate(field string, comparator string, value interface{})
+	WhereYear(field string, comparator string, year int)
+	WhereMonth(field string, comparator string, month int)
+	OrWhere(field string, comparator string, value interface{}, escape bool)
+	WhereGroup(groupFunc queryFunc)
+	OrWhereGroup(groupFunc queryFunc)
 	Or()
 	And()
 	OpenBracket()
@@ -55,8 +101,47 @@ type DB interface {
 	OffsetBy(number int)
 	OrderBy(field string, direction string)
 	GroupBy(field ...string)
+	Having(field string, comparator string, value interface{}, escape bool)
+	OrHaving(field string, comparator string, value interface{}, escape bool)
+	HavingIn(field string, values []interface{}, escape bool)
+	HavingNotIn(field string, values []interface{}, escape bool)
+	OpenHavingBracket()
+	OrOpenHavingBracket()
+	CloseHavingBracket()
+	With(name string, sub DB)
+	WithRecursive(name string, anchor DB, recursive DB)
+	Union(other DB)
+	UnionAll(other DB)
+	Intersect(other DB)
+	Except(other DB)
+	Validate() error
+	Returning(cols ...string)
+	LockForUpdate()
+	SharedLock()
+	SkipLocked()
+	NoWait()
 	Save() (sql.Result, error)
+	SaveContext(ctx context.Context) (sql.Result, error)
+	SaveReturning() (*sql.Rows, context.CancelFunc, error)
+	SaveReturningContext(ctx context.Context) (*sql.Rows, context.CancelFunc, error)
 	Delete() (sql.Result, error)
+	DeleteContext(ctx context.Context) (sql.Result, error)
+	DeleteReturning() (*sql.Rows, context.CancelFunc, error)
+	DeleteReturningContext(ctx context.Context) (*sql.Rows, context.CancelFunc, error)
 	Fetch() (*sql.Rows, context.CancelFunc, error)
+	FetchContext(ctx context.Context) (*sql.Rows, context.CancelFunc, error)
+	FetchInto(dest interface{}) error
+	FetchIntoContext(ctx context.Context, dest interface{}) error
+	FirstInto(dest interface{}) error
+	FirstIntoContext(ctx context.Context, dest interface{}) error
+	FetchMaps() ([]map[string]interface{}, error)
+	FetchMapsContext(ctx context.Context) ([]map[string]interface{}, error)
+	Columns() ([]Column, error)
+	ColumnsContext(ctx context.Context) ([]Column, error)
+	Begin(ctx context.Context) (Tx, error)
+	Transaction(fn func(tx Tx) error) error
+	TransactionContext(ctx context.Context, fn func(tx Tx) error) error
+	TransactionRetry(ctx context.Context, policy RetryPolicy, fn func(tx Tx) error) (int, error)
+	AcquireLock(ctx context.Context, name string, timeout time.Duration) (Lock, error)
 	FetchConcurrent() (successChannel chan bool, startRowsChannel chan bool, rowChannel chan *sql.Rows, nextChannel chan bool, completeChannel chan bool, cancelChannel chan bool, errorChannel chan error)
 }
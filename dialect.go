@@ -0,0 +1,170 @@
+package bezsql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// how parameters are written into the generated queries
+type ParamStyle int
+
+const (
+	//? placeholders
+	QuestionParams ParamStyle = iota
+	//$1, $2... placeholders, generated queries use ? and are converted before they are run
+	DollarParams
+	//@name placeholders passed to the driver as sql.Named parameters
+	NamedParams
+)
+
+// how the id of an inserted row is found when running Save
+type InsertIdStrategy int
+
+const (
+	//sql.Result.LastInsertId returns the id of the first inserted row
+	InsertIdFirst InsertIdStrategy = iota
+	//sql.Result.LastInsertId returns the id of the last inserted row
+	InsertIdLast
+	//the insert is run with RETURNING id and the lowest returned id is used
+	InsertIdReturning
+	//SCOPE_IDENTITY() is selected after the insert
+	InsertIdScopeIdentity
+)
+
+// how UPDATE and DELETE queries with joins are written
+type JoinedWriteStyle int
+
+const (
+	//UPDATE t JOIN u ON ... SET ... and DELETE t FROM t JOIN u ON ...
+	JoinedWriteInline JoinedWriteStyle = iota
+	//UPDATE t SET ... FROM t JOIN u ON ... and DELETE t FROM t JOIN u ON ...
+	JoinedWriteFrom
+	//UPDATE t SET ... WHERE rowid IN (SELECT t.rowid FROM t JOIN u ON ...), the set values can't use the joined tables
+	JoinedWriteRowId
+)
+
+// the row lock taken by a select
+type LockMode int
+
+const (
+	LockNone LockMode = iota
+	//locks the rows against updates and other locks
+	LockUpdate
+	//locks the rows against updates while still letting other transactions read and share lock them
+	LockShared
+)
+
+// what a locking select does when rows are locked by another transaction
+type LockWait int
+
+const (
+	//waits for the other transaction to release the lock
+	LockWaitDefault LockWait = iota
+	//leaves the locked rows out of the results
+	LockSkipLocked
+	//errors instead of waiting
+	LockNoWait
+)
+
+// the part of a date time column compared by WhereDate, WhereYear and WhereMonth
+type DatePart string
+
+const (
+	DatePartDate  DatePart = "date"
+	DatePartYear  DatePart = "year"
+	DatePartMonth DatePart = "month"
+)
+
+// Dialect contains everything that differs between database types, the query builder
+// uses it to generate and run queries so new databases can be added with RegisterDialect
+type Dialect interface {
+	//opens the connection pool for a database config
+	Connect(config Config) (*sql.DB, error)
+	ParamStyle() ParamStyle
+	//wraps an identifier that clashes with a reserved word
+	QuoteIdentifier(identifier string) string
+	//renders the limit and offset of a select, ordered is true when the query has an ORDER BY
+	LimitOffset(limit int, offset int, ordered bool) string
+	InsertIdStrategy() InsertIdStrategy
+	//queries used by DoesTableExist and DoesColumnExist, each should select a single count
+	TableExistsQuery(config Config, table string) (string, []interface{})
+	ColumnExistsQuery(config Config, table string, column string) (string, []interface{})
+}
+
+var dialects map[string]Dialect = map[string]Dialect{
+	"MySQL":      &mySQL{},
+	"SQLServer":  &sQLServer{},
+	"PostgreSQL": &postgreSQL{},
+	"SQLite":     &sQLite{},
+}
+
+// registers a dialect so it can be used as the Type of a connection config
+func RegisterDialect(name string, dialect Dialect) {
+	dialects[name] = dialect
+}
+
+// renders the VALUES of an insert
+func insertRows(rows [][]string) string {
+	insertRows := []string{}
+	for _, row := range rows {
+		insertRows = append(insertRows, fmt.Sprintf("(%s)", strings.Join(row, ",")))
+	}
+	return strings.Join(insertRows, ",")
+}
+
+// renders an INSERT ... ON CONFLICT upsert, used by PostgreSQL and SQLite
+func onConflictUpsert(table string, columns []string, rows [][]string, conflictColumns []string, updateColumns []string) string {
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT (%s) ", table, strings.Join(columns, ","), insertRows(rows), strings.Join(conflictColumns, ","))
+	if len(updateColumns) == 0 {
+		return query + "DO NOTHING"
+	}
+	updates := []string{}
+	for _, column := range updateColumns {
+		updates = append(updates, fmt.Sprintf("%s = excluded.%s", column, column))
+	}
+	return query + "DO UPDATE SET " + strings.Join(updates, ",")
+}
+
+// converts the ? placeholders of a generated query into numbered $ placeholders
+func rebindDollarParams(query string) string {
+	var rebound strings.Builder
+	paramNum := 0
+	var quote rune
+	for _, char := range query {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '?':
+			paramNum++
+			rebound.WriteString(fmt.Sprintf("$%d", paramNum))
+			continue
+		}
+		rebound.WriteRune(char)
+	}
+	return rebound.String()
+}
+
+// renders a RETURNING clause added to the end of a query
+func returningClause(columns []string) (string, bool) {
+	return fmt.Sprintf("RETURNING %s", strings.Join(columns, ",")), false
+}
+
+// renders a FOR UPDATE or FOR SHARE suffix, used by MySQL, PostgreSQL and dialects without LockDialect
+func forLockSuffix(mode LockMode, wait LockWait) (string, string) {
+	suffix := "FOR UPDATE"
+	if mode == LockShared {
+		suffix = "FOR SHARE"
+	}
+	switch wait {
+	case LockSkipLocked:
+		suffix += " SKIP LOCKED"
+	case LockNoWait:
+		suffix += " NOWAIT"
+	}
+	return "", suffix
+}
@@ -17,16 +17,12 @@ func Open(database string) (DB, error) {
 		return nil, errors.New("database not found")
 	}
 
-	var db DB
 	dbConfig := connectionConfigs[database]
-	dbType := dbConfig.Type
-	switch dbType {
-	case "MySQL":
-		db = &mySQL{}
-	case "SQLServer":
-		db = &sQLServer{}
-		db.SetParamPrefix("param")
+	dialect, exists := dialects[dbConfig.Type]
+	if !exists {
+		return nil, fmt.Errorf("database type %s not supported", dbConfig.Type)
 	}
+	db := newBuilder(dialect)
 
 	_, err := db.connect(database, dbConfig)
 	if err != nil {
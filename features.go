@@ -0,0 +1,160 @@
+package bezsql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// the Dialect interface only covers what every database needs, features which differ between databases are
+// added by also implementing the interfaces below, dialects which don't fall back to standard SQL or an error
+
+// adds words which need quoting as a table or column name on this database to the shared reserved words
+type ReservedWordsDialect interface {
+	ReservedWords() []string
+}
+
+func dialectReservedWords(dialect Dialect) []string {
+	if d, ok := dialect.(ReservedWordsDialect); ok {
+		return d.ReservedWords()
+	}
+	return nil
+}
+
+// changes the queries used by Savepoint and RollbackTo, SAVEPOINT and ROLLBACK TO SAVEPOINT are used otherwise
+type SavepointDialect interface {
+	SavepointQuery(name string) string
+	RollbackToQuery(name string) string
+}
+
+func savepointQuery(dialect Dialect, name string) string {
+	if d, ok := dialect.(SavepointDialect); ok {
+		return d.SavepointQuery(name)
+	}
+	return fmt.Sprintf("SAVEPOINT %s", name)
+}
+
+func rollbackToQuery(dialect Dialect, name string) string {
+	if d, ok := dialect.(SavepointDialect); ok {
+		return d.RollbackToQuery(name)
+	}
+	return fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", name)
+}
+
+// lets TransactionRetry recognise deadlocks and lock timeouts, no errors are retried otherwise
+type RetryableDialect interface {
+	//reports whether an error is a deadlock or lock timeout which can be fixed by running the transaction again
+	IsRetryable(err error) bool
+}
+
+func isRetryable(dialect Dialect, err error) bool {
+	if d, ok := dialect.(RetryableDialect); ok {
+		return d.IsRetryable(err)
+	}
+	return false
+}
+
+// changes the keyword starting a WITH clause containing a recursive common table expression, WITH RECURSIVE otherwise
+type RecursiveWithDialect interface {
+	WithRecursiveKeyword() string
+}
+
+func withRecursiveKeyword(dialect Dialect) string {
+	if d, ok := dialect.(RecursiveWithDialect); ok {
+		return d.WithRecursiveKeyword()
+	}
+	return "WITH RECURSIVE"
+}
+
+// reports whether FULL OUTER JOIN is supported, full joins are emulated with a left and a right join otherwise
+type FullJoinDialect interface {
+	SupportsFullJoin() bool
+}
+
+func supportsFullJoin(dialect Dialect) bool {
+	if d, ok := dialect.(FullJoinDialect); ok {
+		return d.SupportsFullJoin()
+	}
+	return false
+}
+
+// changes the join used by CrossApply and OuterApply, apply joins aren't supported otherwise
+type LateralJoinDialect interface {
+	//the join type and whether it needs an ON condition
+	LateralJoin(outer bool) (string, bool)
+}
+
+// returns the join type, whether it needs an ON condition, and whether apply joins are supported
+func lateralJoin(dialect Dialect, outer bool) (string, bool, bool) {
+	if d, ok := dialect.(LateralJoinDialect); ok {
+		joinType, needsOn := d.LateralJoin(outer)
+		return joinType, needsOn, true
+	}
+	return "", false, false
+}
+
+// changes how WhereILike compares without case, LOWER(field) LIKE LOWER(placeholder) otherwise
+type ILikeDialect interface {
+	//renders a case insensitive LIKE comparing field to the placeholder
+	ILike(field string, placeholder string) string
+}
+
+func iLike(dialect Dialect, field string, placeholder string) string {
+	if d, ok := dialect.(ILikeDialect); ok {
+		return d.ILike(field, placeholder)
+	}
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", field, placeholder)
+}
+
+// changes how WhereDate, WhereYear and WhereMonth extract part of a date time column, CAST AS DATE and EXTRACT otherwise
+type DatePartDialect interface {
+	//extracts part of a date time column, years and months should be returned as numbers
+	DatePart(part DatePart, field string) string
+}
+
+func datePart(dialect Dialect, part DatePart, field string) string {
+	if d, ok := dialect.(DatePartDialect); ok {
+		return d.DatePart(part, field)
+	}
+	if part == DatePartDate {
+		return fmt.Sprintf("CAST(%s AS DATE)", field)
+	}
+	return fmt.Sprintf("EXTRACT(%s FROM %s)", strings.ToUpper(string(part)), field)
+}
+
+// adds Upsert and UpsertMulti, saving an upsert returns an error otherwise
+type UpsertDialect interface {
+	//renders an insert of rows which updates updateColumns of the existing row when it conflicts on conflictColumns,
+	//rows contain the placeholders of each row's values
+	Upsert(table string, columns []string, rows [][]string, conflictColumns []string, updateColumns []string) string
+	//whether saving an upsert without conflict columns should return an error
+	UpsertNeedsConflictColumns() bool
+}
+
+// adds joins to updates and deletes, saving an update or running a delete with joins returns an error otherwise
+type JoinedWriteDialect interface {
+	//how joins are added to updates and deletes, and for JoinedWriteRowId the column identifying a row
+	JoinedWriteStyle() (JoinedWriteStyle, string)
+}
+
+// changes the clause added by Returning, a RETURNING clause at the end of the query otherwise
+type ReturningDialect interface {
+	//renders the clause returning columns of the inserted, updated or deleted rows, deleted is true for deletes,
+	//output is true if the clause goes after the insert columns, update SET or delete table instead of at the end
+	ReturningClause(columns []string, deleted bool) (clause string, output bool)
+}
+
+// changes the row locks taken by LockForUpdate and SharedLock, a FOR UPDATE or FOR SHARE suffix otherwise
+type LockDialect interface {
+	//renders a row lock for a select as a table hint added after the FROM table and/or a suffix added to the end
+	LockClause(mode LockMode, wait LockWait) (tableHint string, suffix string)
+}
+
+// adds AcquireLock, which returns an error otherwise
+type NamedLockDialect interface {
+	//query selecting 1 if the named session lock was acquired within timeout and 0 if it wasn't,
+	//AcquireLock runs it again until the timeout passes so it can try once instead of waiting
+	AcquireLockQuery(name string, timeout time.Duration) (string, []interface{}, error)
+	//query selecting 1 if the named session lock was released
+	ReleaseLockQuery(name string) (string, []interface{})
+}
@@ -0,0 +1,149 @@
+package bezsql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// matches a string literal, a named parameter, or a column with or without its table
+var fullJoinTokenPattern = regexp.MustCompile("'(?:[^']|'')*'|@\\w+|(`[^`]+`|\"[^\"]+\"|\\[[^\\]]+\\]|[A-Za-z_]\\w*)(?:\\.(`[^`]+`|\"[^\"]+\"|\\[[^\\]]+\\]|[A-Za-z_]\\w*))?")
+
+// the columns of the derived table an emulated full join selects from, columns used by the select,
+// group, having and order of the query are selected by both halves of the join under a new name
+type fullJoinColumns struct {
+	//columns selected without their table, which are only renamed when used the same way elsewhere
+	bareCols map[string]bool
+	aliases  map[string]string
+	//the renamed columns in the order they were found
+	columns []string
+}
+
+func newFullJoinColumns(cols []string) *fullJoinColumns {
+	c := fullJoinColumns{
+		bareCols: map[string]bool{},
+		aliases:  map[string]string{},
+	}
+	for _, col := range cols {
+		if match := fullJoinTokenPattern.FindStringSubmatch(col); match != nil && match[0] == col && match[1] != "" && match[2] == "" {
+			c.bareCols[col] = true
+		}
+	}
+	return &c
+}
+
+// renames the columns used in an expression to the columns of the derived table
+func (c *fullJoinColumns) rename(expression string) string {
+	return fullJoinTokenPattern.ReplaceAllStringFunc(expression, func(token string) string {
+		match := fullJoinTokenPattern.FindStringSubmatch(token)
+		if match[1] == "" || (match[2] == "" && !c.bareCols[token]) {
+			return token
+		}
+		alias, exists := c.aliases[token]
+		if !exists {
+			alias = fmt.Sprintf("full_join_col%d", len(c.columns)+1)
+			c.aliases[token] = alias
+			c.columns = append(c.columns, token)
+		}
+		return alias
+	})
+}
+
+// renames a selected column, keeping the name the column would have had if it's a column on its own
+func (c *fullJoinColumns) renameSelected(col string) string {
+	match := fullJoinTokenPattern.FindStringSubmatch(col)
+	if match == nil || match[0] != col || match[1] == "" {
+		return c.rename(col)
+	}
+	name := match[1]
+	if match[2] != "" {
+		name = match[2]
+	}
+	return fmt.Sprintf("%s AS %s", c.rename(col), name)
+}
+
+// the columns selected by each half of the join
+func (c *fullJoinColumns) selected() string {
+	if len(c.columns) == 0 {
+		return "1 AS full_join_row"
+	}
+	selected := []string{}
+	for _, column := range c.columns {
+		selected = append(selected, fmt.Sprintf("%s AS %s", column, c.aliases[column]))
+	}
+	return strings.Join(selected, ",")
+}
+
+// emulates full joins with the rows of a left join and the rows of a right join that didn't match, the combined
+// rows are selected from a derived table so the grouping and ordering apply to every row, returns the ordering
+// with its columns renamed to the columns of the derived table
+func (db *builder) generateFullJoinSelect() (string, []interface{}, []string, []orderBy) {
+	columns := newFullJoinColumns(db.cols)
+	selectCols := []string{}
+	for _, col := range db.cols {
+		selectCols = append(selectCols, columns.renameSelected(col))
+	}
+	groupString := ""
+	if len(db.groupColumns) > 0 {
+		groupString = fmt.Sprintf(" GROUP BY %s", columns.rename(strings.Join(db.groupColumns, ",")))
+	}
+	havingString := ""
+	var havingParams []interface{}
+	havingParamNames := []string{}
+	if len(db.having.wheres) > 0 {
+		havingString, havingParams, havingParamNames = db.having.ApplyWheres()
+		havingString = " HAVING " + columns.rename(havingString)
+	}
+	ordering := []orderBy{}
+	for _, o := range db.ordering {
+		ordering = append(ordering, orderBy{
+			Field:     columns.rename(o.Field),
+			Direction: o.Direction,
+		})
+	}
+
+	namedParams := db.dialect.ParamStyle() == NamedParams
+	leftString, params, paramNames := db.generateFullJoinHalf(columns, "LEFT JOIN")
+	rightString, rightParams, _ := db.generateFullJoinHalf(columns, "RIGHT JOIN")
+	//a right join row didn't match if its full join conditions aren't true
+	unmatched := []string{}
+	for _, j := range db.joins {
+		if j.Type != "FULL OUTER JOIN" || len(j.Query.wheres) == 0 {
+			continue
+		}
+		onString, onParams, _ := j.Query.ApplyWheres()
+		unmatched = append(unmatched, fmt.Sprintf("(%s) IS NOT TRUE", onString))
+		rightParams = append(rightParams, onParams...)
+	}
+	if len(unmatched) > 0 {
+		if len(db.query.wheres) > 0 {
+			rightString += " AND "
+		} else {
+			rightString += " WHERE "
+		}
+		rightString += strings.Join(unmatched, " AND ")
+	}
+	//named parameters can be used twice so only need adding once
+	if !namedParams {
+		params = append(params, rightParams...)
+	}
+	params = append(params, havingParams...)
+	paramNames = append(paramNames, havingParamNames...)
+
+	query := fmt.Sprintf("SELECT %s FROM (%s UNION ALL %s) AS full_join_rows%s%s", strings.Join(selectCols, ","), leftString, rightString, groupString, havingString)
+	return query, params, paramNames, ordering
+}
+
+// renders one half of an emulated full join, with full joins rendered as fullJoinType
+func (db *builder) generateFullJoinHalf(columns *fullJoinColumns, fullJoinType string) (string, []interface{}, []string) {
+	query := fmt.Sprintf("SELECT %s FROM %s ", columns.selected(), db.table)
+	joinString, params, paramNames := db.generateJoins(fullJoinType)
+	query += joinString
+	if len(db.query.wheres) > 0 {
+		whereString, newParams, newParamNames := db.query.ApplyWheres()
+		params = append(params, newParams...)
+		paramNames = append(paramNames, newParamNames...)
+		query += fmt.Sprintf(" WHERE (%s)", whereString)
+	}
+	return query, params, paramNames
+}
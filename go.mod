@@ -1,11 +1,15 @@
 module bezberr.com/bezsql
 
-go 1.17
+go 1.18
 
-require github.com/go-sql-driver/mysql v1.6.0
+require (
+	github.com/denisenkom/go-mssqldb v0.11.0
+	github.com/go-sql-driver/mysql v1.6.0
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.16
+)
 
 require (
-	github.com/denisenkom/go-mssqldb v0.11.0 // indirect
 	github.com/golang-sql/civil v0.0.0-20190719163853-cb61b32ac6fe // indirect
 	golang.org/x/crypto v0.0.0-20190325154230-a5d413f7728c // indirect
 )
@@ -0,0 +1,111 @@
+package bezsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// returned by AcquireLock when the lock is still held by another session once the timeout passes
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// how long AcquireLock waits before trying again when the lock query returns without the lock
+const lockRetryInterval = 100 * time.Millisecond
+
+type Lock interface {
+	//releases the lock and returns its connection to the pool, if the lock can't be released the connection is
+	//closed instead so the database ends the session holding the lock
+	ReleaseLock(ctx context.Context) error
+}
+
+// a named lock held by the session of a single connection taken from the pool
+type namedLock struct {
+	dialect NamedLockDialect
+	conn    *sql.Conn
+	name    string
+}
+
+// acquires a named lock shared by every connection to the database, waiting up to timeout for another session
+// to release it, the lock is held by a connection kept out of the pool until ReleaseLock is called
+func (db *builder) AcquireLock(ctx context.Context, name string, timeout time.Duration) (Lock, error) {
+	if timeout < 0 {
+		return nil, errors.New("lock timeout can't be negative")
+	}
+	dialect, ok := db.dialect.(NamedLockDialect)
+	if !ok {
+		return nil, errors.New("named locks aren't supported by this database")
+	}
+	if _, _, err := dialect.AcquireLockQuery(name, timeout); err != nil {
+		return nil, err
+	}
+	conn, err := openConnections[db.databaseName].Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		query, params, _ := dialect.AcquireLockQuery(name, lockTimeRemaining(deadline))
+		var acquired int64
+		if err := conn.QueryRowContext(ctx, query, params...).Scan(&acquired); err != nil {
+			//the lock may have been acquired before the error
+			discardConn(conn)
+			return nil, err
+		}
+		if acquired == 1 {
+			return &namedLock{
+				dialect: dialect,
+				conn:    conn,
+				name:    name,
+			}, nil
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			conn.Close()
+			return nil, ErrLockTimeout
+		}
+		if wait > lockRetryInterval {
+			wait = lockRetryInterval
+		}
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// the time left before deadline, zero once it has passed so the lock query tries once instead of being given
+// a negative timeout, which MySQL and SQL Server treat as waiting forever
+func lockTimeRemaining(deadline time.Time) time.Duration {
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (l *namedLock) ReleaseLock(ctx context.Context) error {
+	query, params := l.dialect.ReleaseLockQuery(l.name)
+	var released int64
+	if err := l.conn.QueryRowContext(ctx, query, params...).Scan(&released); err != nil {
+		discardConn(l.conn)
+		return err
+	}
+	if released != 1 {
+		discardConn(l.conn)
+		return fmt.Errorf("lock %s wasn't held by this connection", l.name)
+	}
+	return l.conn.Close()
+}
+
+// closes a connection which may still hold a lock instead of returning it to the pool,
+// returning driver.ErrBadConn from Raw makes the pool close the driver connection
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(driverConn interface{}) error {
+		return driver.ErrBadConn
+	})
+}
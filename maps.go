@@ -0,0 +1,157 @@
+package bezsql
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column describes a column of a select's results
+type Column struct {
+	Name string
+	//type name reported by the driver, e.g. VARCHAR or INT
+	DatabaseType string
+	//Go type the driver scans the column into
+	ScanType reflect.Type
+	//whether the column allows nulls, only set if HasNullable is true as not every driver reports it
+	Nullable    bool
+	HasNullable bool
+	//length of variable length text and binary columns, only set if HasLength is true
+	Length    int64
+	HasLength bool
+}
+
+// runs the select and returns each row as a map of column name to value, []byte values of text columns
+// are converted to strings and date columns returned as text are parsed into time.Time
+func (db *builder) FetchMaps() ([]map[string]interface{}, error) {
+	return db.FetchMapsContext(context.Background())
+}
+
+func (db *builder) FetchMapsContext(ctx context.Context) ([]map[string]interface{}, error) {
+	results, cancel, err := db.FetchContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer results.Close()
+
+	columnTypes, err := results.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	rows := []map[string]interface{}{}
+	for results.Next() {
+		values := make([]interface{}, len(columnTypes))
+		targets := make([]interface{}, len(columnTypes))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := results.Scan(targets...); err != nil {
+			return nil, err
+		}
+		row := map[string]interface{}{}
+		for i, columnType := range columnTypes {
+			row[columnType.Name()] = convertValue(columnType.DatabaseTypeName(), values[i])
+		}
+		rows = append(rows, row)
+	}
+	if err := results.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// runs the select and returns a description of each column in its results
+func (db *builder) Columns() ([]Column, error) {
+	return db.ColumnsContext(context.Background())
+}
+
+func (db *builder) ColumnsContext(ctx context.Context) ([]Column, error) {
+	results, cancel, err := db.FetchContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer results.Close()
+
+	columnTypes, err := results.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := []Column{}
+	for _, columnType := range columnTypes {
+		column := Column{
+			Name:         columnType.Name(),
+			DatabaseType: columnType.DatabaseTypeName(),
+			ScanType:     columnType.ScanType(),
+		}
+		column.Nullable, column.HasNullable = columnType.Nullable()
+		column.Length, column.HasLength = columnType.Length()
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+var binaryTypes = []string{"BLOB", "BINARY", "BYTEA", "IMAGE"}
+var integerTypes = []string{"INT", "SERIAL"}
+var floatTypes = []string{"FLOAT", "DOUBLE", "REAL"}
+var timeTypes = []string{"DATE", "TIMESTAMP"}
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02",
+}
+
+func isDatabaseType(databaseType string, types []string) bool {
+	for _, t := range types {
+		if strings.Contains(databaseType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// converts a value scanned from the driver into the Go type a caller would expect for the column's database type
+func convertValue(databaseType string, value interface{}) interface{} {
+	databaseType = strings.ToUpper(databaseType)
+	switch v := value.(type) {
+	case []byte:
+		switch {
+		case isDatabaseType(databaseType, binaryTypes):
+			return v
+		case isDatabaseType(databaseType, integerTypes):
+			if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				return i
+			}
+		case isDatabaseType(databaseType, floatTypes):
+			if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+				return f
+			}
+		case isDatabaseType(databaseType, timeTypes):
+			if t, ok := parseTime(string(v)); ok {
+				return t
+			}
+		}
+		return string(v)
+	case string:
+		if isDatabaseType(databaseType, timeTypes) {
+			if t, ok := parseTime(v); ok {
+				return t
+			}
+		}
+	}
+	return value
+}
+
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
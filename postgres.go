@@ -0,0 +1,130 @@
+package bezsql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type postgreSQL struct{}
+
+func (d *postgreSQL) Connect(config Config) (*sql.DB, error) {
+	connectionUrl := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.Username, config.Password),
+		Host:     fmt.Sprintf("%s:%d", config.Host, config.Port),
+		Path:     config.Database,
+		RawQuery: "sslmode=disable",
+	}
+	return sql.Open("postgres", connectionUrl.String())
+}
+
+// lib/pq only understands numbered parameters
+func (d *postgreSQL) ParamStyle() ParamStyle {
+	return DollarParams
+}
+
+func (d *postgreSQL) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf("\"%s\"", identifier)
+}
+
+func (d *postgreSQL) LimitOffset(limit int, offset int, ordered bool) string {
+	query := ""
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d ", limit)
+	}
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d ", offset)
+	}
+	return query
+}
+
+func (d *postgreSQL) InsertIdStrategy() InsertIdStrategy {
+	return InsertIdReturning
+}
+
+func (d *postgreSQL) TableExistsQuery(config Config, table string) (string, []interface{}) {
+	return "SELECT COUNT(*) num FROM information_schema.tables WHERE table_catalog = $1 AND table_schema = current_schema() AND table_name = $2", []interface{}{
+		config.Database,
+		table,
+	}
+}
+
+func (d *postgreSQL) ColumnExistsQuery(config Config, table string, column string) (string, []interface{}) {
+	return "SELECT COUNT(*) num FROM information_schema.columns WHERE table_catalog = $1 AND table_schema = current_schema() AND table_name = $2 AND column_name = $3", []interface{}{
+		config.Database,
+		table,
+		column,
+	}
+}
+
+// user is a function in PostgreSQL so a column named user has to be quoted
+func (d *postgreSQL) ReservedWords() []string {
+	return []string{
+		"user",
+	}
+}
+
+func (d *postgreSQL) SupportsFullJoin() bool {
+	return true
+}
+
+func (d *postgreSQL) LateralJoin(outer bool) (string, bool) {
+	if outer {
+		return "LEFT JOIN LATERAL", true
+	}
+	return "CROSS JOIN LATERAL", false
+}
+
+func (d *postgreSQL) ILike(field string, placeholder string) string {
+	return fmt.Sprintf("%s ILIKE %s", field, placeholder)
+}
+
+func (d *postgreSQL) DatePart(part DatePart, field string) string {
+	switch part {
+	case DatePartYear:
+		return fmt.Sprintf("EXTRACT(YEAR FROM %s)", field)
+	case DatePartMonth:
+		return fmt.Sprintf("EXTRACT(MONTH FROM %s)", field)
+	}
+	return fmt.Sprintf("CAST(%s AS DATE)", field)
+}
+
+func (d *postgreSQL) Upsert(table string, columns []string, rows [][]string, conflictColumns []string, updateColumns []string) string {
+	return onConflictUpsert(table, columns, rows, conflictColumns, updateColumns)
+}
+
+func (d *postgreSQL) UpsertNeedsConflictColumns() bool {
+	return true
+}
+
+// PostgreSQL's UPDATE ... FROM and DELETE ... USING can't left join to the updated table so rows are matched by ctid
+func (d *postgreSQL) JoinedWriteStyle() (JoinedWriteStyle, string) {
+	return JoinedWriteRowId, "ctid"
+}
+
+// advisory locks use a number so the name is hashed, the lock is only tried once and AcquireLock retries it
+func (d *postgreSQL) AcquireLockQuery(name string, timeout time.Duration) (string, []interface{}, error) {
+	return "SELECT CASE WHEN pg_try_advisory_lock(hashtext($1)) THEN 1 ELSE 0 END", []interface{}{
+		name,
+	}, nil
+}
+
+func (d *postgreSQL) ReleaseLockQuery(name string) (string, []interface{}) {
+	return "SELECT CASE WHEN pg_advisory_unlock(hashtext($1)) THEN 1 ELSE 0 END", []interface{}{
+		name,
+	}
+}
+
+// 40001 is a serialization failure, 40P01 a deadlock and 55P03 a lock timeout
+func (d *postgreSQL) IsRetryable(err error) bool {
+	var postgreSQLErr *pq.Error
+	if errors.As(err, &postgreSQLErr) {
+		return postgreSQLErr.Code == "40001" || postgreSQLErr.Code == "40P01" || postgreSQLErr.Code == "55P03"
+	}
+	return false
+}
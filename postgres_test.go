@@ -0,0 +1,3028 @@
+package bezsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func init() {
+	SetConnections(map[string]Config{
+		"sqlserver_test": {
+			Type:     "SQLServer",
+			Host:     "localhost",
+			Port:     1433,
+			Username: "sa",
+			Password: "SuperSecurePassword!",
+			Database: "test",
+		},
+		"mysql_test": {
+			Type:     "MySQL",
+			Host:     "localhost",
+			Port:     3306,
+			Username: "root",
+			Password: "",
+			Database: "test",
+		},
+		"postgres_test": {
+			Type:     "PostgreSQL",
+			Host:     "localhost",
+			Port:     5432,
+			Username: "postgres",
+			Password: "",
+			Database: "test",
+		},
+		"sqlite_test": {
+			Type:     "SQLite",
+			Database: ":memory:",
+		},
+	})
+	db, err := Open("postgres_test")
+	if err != nil {
+		panic("no test database found")
+	}
+
+	var params []interface{}
+	if t, err := db.DoesTableExist("users"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE IF NOT EXISTS users (",
+			"id SERIAL NOT NULL,",
+			"title_id INT NOT NULL,",
+			"first_name VARCHAR(50) NOT NULL,",
+			"surname VARCHAR(50) NOT NULL,",
+			"email VARCHAR(200) DEFAULT NULL,",
+			"gender_id INT NOT NULL,",
+			"date_of_birth TIMESTAMP NOT NULL,",
+			"phone_number VARCHAR(50) NOT NULL,",
+			"city_id INT NOT NULL,",
+			"country_id INT DEFAULT NULL,",
+			"postcode VARCHAR(100) NOT NULL,",
+			"street_address VARCHAR(100) NOT NULL,",
+			"active SMALLINT DEFAULT 0,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		_, er := db.RawNonQuery(createTableQuery, params)
+		if er != nil {
+			fmt.Println(er)
+		}
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE users RESTART IDENTITY;", params)
+	}
+
+	users := [][]interface{}{
+		{1, "Steve", "Berridge", "ste@ber.com", 1, "1993-07-12 00:00:00", "07434534534534", 3, 1, "DE76 YAS", "123 Fake Street", 1},
+		{1, "Bob", "Briar", nil, 1, "1999-08-27 00:00:00", "07123564334555", 4, nil, "DE71 AXC", "14 Boller Road", 0},
+		{1, "Sharon", "Pollard", "shar@pol.com", 2, "1967-03-12 00:00:00", "076453434553345", 2, 1, "DE71 AXC", "14 Boller Road", 0},
+		{1, "Juliet", "Jones", "jules@jones.com", 2, "1985-06-01 00:00:00", "079874636334544", 1, 1, "ST54 POC", "1 Everet Avenue", 1},
+	}
+
+	insertUserDb, _ := db.NewQuery()
+	insertUserDb.Table("users")
+	insertUserDb.InsertMulti([]string{
+		"title_id",
+		"first_name",
+		"surname",
+		"email",
+		"gender_id",
+		"date_of_birth",
+		"phone_number",
+		"city_id",
+		"country_id",
+		"postcode",
+		"street_address",
+		"active",
+	}, users, true)
+	insertUserDb.Save()
+
+	if t, err := db.DoesTableExist("titles"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE titles (",
+			"id SERIAL NOT NULL,",
+			"title VARCHAR(10) NOT NULL,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		db.RawNonQuery(createTableQuery, params)
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE titles RESTART IDENTITY;", params)
+	}
+
+	insertTitleDb, _ := db.NewQuery()
+	insertTitleDb.Table("titles")
+	insertTitleDb.Insert(map[string]interface{}{
+		"title": "Mr",
+	}, true)
+	insertTitleDb.Save()
+
+	if t, err := db.DoesTableExist("genders"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE genders (",
+			"id SERIAL NOT NULL,",
+			"gender VARCHAR(10) NOT NULL,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		db.RawNonQuery(createTableQuery, params)
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE genders RESTART IDENTITY;", params)
+	}
+
+	genders := [][]interface{}{
+		{"Male"},
+		{"Female"},
+	}
+
+	insertGenderDb, _ := db.NewQuery()
+	insertGenderDb.Table("genders")
+	insertGenderDb.InsertMulti([]string{
+		"gender",
+	}, genders, true)
+	insertGenderDb.Save()
+
+	if t, err := db.DoesTableExist("countries"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE countries (",
+			"id SERIAL NOT NULL,",
+			"country VARCHAR(50) NOT NULL,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		db.RawNonQuery(createTableQuery, params)
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE countries RESTART IDENTITY;", params)
+	}
+
+	insertCountryDb, _ := db.NewQuery()
+	insertCountryDb.Table("countries")
+	insertCountryDb.Insert(map[string]interface{}{
+		"country": "United Kingdom",
+	}, true)
+	insertCountryDb.Save()
+
+	if t, err := db.DoesTableExist("cities"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE cities (",
+			"id SERIAL NOT NULL,",
+			"city VARCHAR(50) NOT NULL,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		db.RawNonQuery(createTableQuery, params)
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE cities RESTART IDENTITY;", params)
+	}
+
+	cities := [][]interface{}{
+		{"Derby"},
+		{"Birmingham"},
+		{"Burton-on-Trent"},
+		{"London"},
+	}
+
+	insertCityDb, _ := db.NewQuery()
+	insertCityDb.Table("cities")
+	insertCityDb.InsertMulti([]string{
+		"city",
+	}, cities, true)
+	insertCityDb.Save()
+
+	if t, err := db.DoesTableExist("user_settings"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE user_settings (",
+			"id SERIAL NOT NULL,",
+			"user_id INT NOT NULL,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		db.RawNonQuery(createTableQuery, params)
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE user_settings RESTART IDENTITY;", params)
+	}
+
+	insertUserSettingsDb, _ := db.NewQuery()
+	insertUserSettingsDb.Table("user_settings")
+	insertUserSettingsDb.Insert(map[string]interface{}{
+		"user_id": 1,
+	}, true)
+	insertUserSettingsDb.Save()
+
+	insertUserSettingsDb, _ = db.NewQuery()
+	insertUserSettingsDb.Table("user_settings")
+	insertUserSettingsDb.Insert(map[string]interface{}{
+		"user_id": 2,
+	}, true)
+	insertUserSettingsDb.Save()
+
+	if t, err := db.DoesTableExist("parties"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE parties (",
+			"id SERIAL NOT NULL,",
+			"date TIMESTAMP NOT NULL,",
+			"city_id INT NOT NULL,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		db.RawNonQuery(createTableQuery, params)
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE parties RESTART IDENTITY;", params)
+	}
+
+	insertPartyDb, _ := db.NewQuery()
+	insertPartyDb.Table("parties")
+	insertPartyDb.Insert(map[string]interface{}{
+		"date":    "2021-10-01 18:00:00",
+		"city_id": 1,
+	}, true)
+	insertPartyDb.Save()
+
+	if t, err := db.DoesTableExist("party_guests"); err == nil && !t {
+		createTableQuery := strings.Join([]string{
+			"CREATE TABLE party_guests (",
+			"id SERIAL NOT NULL,",
+			"user_id INT NOT NULL,",
+			"party_id INT NOT NULL,",
+			"accepted SMALLINT NOT NULL DEFAULT 0,",
+			"PRIMARY KEY (id)",
+			");",
+		}, "")
+		db.RawNonQuery(createTableQuery, params)
+	} else {
+		db.RawNonQuery("TRUNCATE TABLE party_guests RESTART IDENTITY;", params)
+	}
+
+	guests := [][]interface{}{
+		{1, 1, 0},
+		{2, 1, 0},
+		{3, 1, 1},
+	}
+
+	insertGuestDb, _ := db.NewQuery()
+	insertGuestDb.Table("party_guests")
+	insertGuestDb.InsertMulti([]string{
+		"user_id",
+		"party_id",
+		"accepted",
+	}, guests, true)
+	insertGuestDb.Save()
+
+}
+
+func TestPostgreSQLSelect(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"surname",
+	})
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		var (
+			id      int64
+			surname string
+		)
+		rowNum++
+		res.Scan(&id, &surname)
+	}
+	if rowNum != 4 {
+		t.Fatalf("Failed fetching rows, expected 2 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSelectBasicWhere(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"surname",
+	})
+	db.Where("id", "=", 1, true)
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		var (
+			id      int64
+			surname string
+		)
+		rowNum++
+		res.Scan(&id, &surname)
+		if id != 1 {
+			t.Fatalf("Failed fetching correct row, expected id 1, got %d", id)
+		}
+	}
+	if rowNum != 1 {
+		t.Fatalf("Failed fetching rows, expected 1 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSelectComplexWhere(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"active",
+		"date_of_birth",
+	})
+	db.OpenBracket()
+	db.Where("active", "=", 1, true)
+	db.Where("date_of_birth", ">", "1980-01-01 00:00:00", true)
+	db.CloseBracket()
+	db.Or()
+	db.OpenBracket()
+	db.Where("first_name", "=", "Sharon", true)
+	db.CloseBracket()
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		var (
+			id            int64
+			first_name    string
+			active        bool
+			date_of_birth string
+		)
+		rowNum++
+		res.Scan(&id, &first_name, &active, &date_of_birth)
+		if !((active && date_of_birth > "1980-01-01 00:00:00") || (first_name == "Sharon")) {
+			t.Fatalf("Found invalid rows, should be (active AND date of birth > 1980-01-01) OR (first_name = 'Sharon'), got %v, %s, %s", active, date_of_birth, first_name)
+		}
+	}
+	if rowNum != 3 {
+		t.Fatalf("Failed fetching rows, expected 3 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSubQuery(t *testing.T) {
+	usersdb, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	usersdb.Table("users")
+	usersdb.Cols([]string{
+		"id",
+	})
+
+	mainDb, _ := usersdb.NewQuery()
+	mainDb.TableSub(usersdb, "my_users")
+	mainDb.Cols([]string{
+		"my_users.id",
+	})
+	mainDb.Where("my_users.id", "=", 1, true)
+	res, close, err := mainDb.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running sub query, got %s", err.Error())
+	}
+	defer close()
+	totalRows := 0
+	for res.Next() {
+		totalRows++
+		var id int
+		res.Scan(&id)
+		if id != 1 {
+			t.Fatalf("Expected id 1, got %d", id)
+		}
+	}
+	if totalRows == 0 {
+		t.Fatalf("No results returned")
+	}
+}
+
+func TestPostgreSQLAggregate(t *testing.T) {
+	countdb, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	countdb.Table("users")
+	countdb.Cols([]string{
+		countdb.Count("id", "counted"),
+	})
+
+	sumdb, _ := countdb.NewQuery()
+	sumdb.Table("cities")
+	sumdb.Cols([]string{
+		sumdb.Sum("id", "summed"),
+	})
+
+	avgdb, _ := countdb.NewQuery()
+	avgdb.Table("users")
+	avgdb.Cols([]string{
+		avgdb.Avg("gender_id", "avged"),
+	})
+
+	mindb, _ := countdb.NewQuery()
+	mindb.Table("users")
+	mindb.Cols([]string{
+		mindb.Min("date_of_birth", "youngest"),
+	})
+
+	maxdb, _ := countdb.NewQuery()
+	maxdb.Table("users")
+	maxdb.Cols([]string{
+		maxdb.Max("date_of_birth", "oldest"),
+	})
+
+	results := ConcurrentFetch(countdb, sumdb, avgdb, mindb, maxdb)
+
+	for i, res := range results {
+		switch i {
+		case 0:
+			//count
+			if len(res.Errors) > 0 {
+				t.Fatalf("Fetching count failed with errors %s", joinErrors(res.Errors))
+			} else {
+				totalRows := 0
+				res.StartRowsChannel <- true
+				done := false
+				for {
+					select {
+					case row := <-res.RowChannel:
+						totalRows++
+						var count int
+						row.Scan(&count)
+						if count == 0 {
+							t.Fatal("Count returned 0")
+						}
+						res.NextChannel <- true
+
+					case <-res.CompleteChannel:
+						done = true
+					}
+					if done {
+						break
+					}
+				}
+			}
+		case 1:
+			//sum
+			if len(res.Errors) > 0 {
+				t.Fatalf("Fetching sum failed with errors %s", joinErrors(res.Errors))
+			} else {
+				totalRows := 0
+				res.StartRowsChannel <- true
+				done := false
+				for {
+					select {
+					case row := <-res.RowChannel:
+						totalRows++
+						var sum int
+						row.Scan(&sum)
+						if sum == 0 {
+							t.Fatal("Sum returned 0")
+						}
+						res.NextChannel <- true
+
+					case <-res.CompleteChannel:
+						done = true
+					}
+					if done {
+						break
+					}
+				}
+			}
+		case 2:
+			//avg
+			if len(res.Errors) > 0 {
+				t.Fatalf("Fetching avg failed with errors %s", joinErrors(res.Errors))
+			} else {
+				totalRows := 0
+				res.StartRowsChannel <- true
+				done := false
+				for {
+					select {
+					case row := <-res.RowChannel:
+						totalRows++
+						var avg float32
+						row.Scan(&avg)
+						if avg == 0 {
+							t.Fatal("Avg returned 0")
+						}
+						res.NextChannel <- true
+
+					case <-res.CompleteChannel:
+						done = true
+					}
+					if done {
+						break
+					}
+				}
+			}
+		case 3:
+			//min
+			if len(res.Errors) > 0 {
+				t.Fatalf("Fetching min failed with errors %s", joinErrors(res.Errors))
+			} else {
+				totalRows := 0
+				res.StartRowsChannel <- true
+				done := false
+				for {
+					select {
+					case row := <-res.RowChannel:
+						totalRows++
+						var min string
+						row.Scan(&min)
+						if min != "1967-03-12T00:00:00Z" {
+							t.Fatalf("Failed fetching min date of birth, got %s", min)
+						}
+						res.NextChannel <- true
+
+					case <-res.CompleteChannel:
+						done = true
+					}
+					if done {
+						break
+					}
+				}
+			}
+		case 4:
+			//max
+			if len(res.Errors) > 0 {
+				t.Fatalf("Fetching max failed with errors %s", joinErrors(res.Errors))
+			} else {
+				totalRows := 0
+				res.StartRowsChannel <- true
+				done := false
+				for {
+					select {
+					case row := <-res.RowChannel:
+						totalRows++
+						var max string
+						row.Scan(&max)
+						if max != "1999-08-27T00:00:00Z" {
+							t.Fatalf("Failed fetching max date of birth, got %s", max)
+						}
+						res.NextChannel <- true
+
+					case <-res.CompleteChannel:
+						done = true
+					}
+					if done {
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPostgreSQLSelectWhereNull(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"surname",
+		"email",
+	})
+	db.WhereNull("email")
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		var (
+			id      int64
+			surname string
+			email   sql.NullString
+		)
+		rowNum++
+		res.Scan(&id, &surname, &email)
+		if email.Valid {
+			t.Fatal("Failed fetching where null")
+		}
+	}
+	if rowNum != 1 {
+		t.Fatalf("Failed fetching rows, expected 1 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSelectWhereNotNull(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"surname",
+		"email",
+	})
+	db.WhereNotNull("email")
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		var (
+			id      int64
+			surname string
+			email   sql.NullString
+		)
+		rowNum++
+		res.Scan(&id, &surname, &email)
+		if !email.Valid {
+			t.Fatal("Failed fetching where not null")
+		}
+	}
+	if rowNum != 3 {
+		t.Fatalf("Failed fetching rows, expected 3 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSelectWhereInList(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.Cols([]string{
+		"id",
+		"city",
+	})
+	db.WhereInList("city", []interface{}{
+		"Derby",
+		"Birmingham",
+	}, true)
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		var (
+			id   int64
+			city string
+		)
+		rowNum++
+		res.Scan(&id, &city)
+		if city != "Derby" && city != "Birmingham" {
+			t.Fatalf("Invalid result returned, got %s", city)
+		}
+	}
+	if rowNum != 2 {
+		t.Fatalf("Failed fetching rows, expected 2 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSelectWhereNotInList(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.Cols([]string{
+		"id",
+		"city",
+	})
+	db.WhereNotInList("city", []interface{}{
+		"Derby",
+		"Birmingham",
+	}, true)
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		var (
+			id   int64
+			city string
+		)
+		rowNum++
+		res.Scan(&id, &city)
+		if city != "Burton-on-Trent" && city != "London" {
+			t.Fatalf("Invalid result returned, got %s", city)
+		}
+	}
+	if rowNum != 2 {
+		t.Fatalf("Failed fetching rows, expected 2 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSelectWhereInSub(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"gender_id",
+	})
+
+	subDb, _ := db.NewQuery()
+	subDb.Table("genders")
+	subDb.Cols([]string{
+		"id",
+	})
+	subDb.Where("gender", "=", "Female", true)
+
+	db.WhereInSub("gender_id", subDb)
+
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	rowNum := 0
+	for res.Next() {
+		rowNum++
+		var (
+			gender_id int32
+		)
+		res.Scan(&gender_id)
+		if gender_id != 2 {
+			t.Fatalf("Invalid result returned, expected 2 got %d", gender_id)
+		}
+	}
+	if rowNum == 0 {
+		t.Fatalf("No results found")
+	}
+
+}
+
+func TestPostgreSQLSelectWhereNotInSub(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"gender_id",
+	})
+
+	subDb, _ := db.NewQuery()
+	subDb.Table("genders")
+	subDb.Cols([]string{
+		"id",
+	})
+	subDb.Where("gender", "=", "Female", true)
+
+	db.WhereNotInSub("gender_id", subDb)
+
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	rowNum := 0
+	for res.Next() {
+		rowNum++
+		var (
+			gender_id int32
+		)
+		res.Scan(&gender_id)
+		if gender_id != 1 {
+			t.Fatalf("Invalid result returned, expected 1 got %d", gender_id)
+		}
+	}
+	if rowNum == 0 {
+		t.Fatalf("No results found")
+	}
+
+}
+
+func TestPostgreSQLInsertAndDelete(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.Insert(map[string]interface{}{
+		"city": "Belper",
+	}, true)
+	res, _ := db.Save()
+
+	if r, _ := res.RowsAffected(); r == 0 {
+		t.Fatalf("Record not inserted")
+	} else {
+		id, _ := res.LastInsertId()
+		deleteDb, _ := db.NewQuery()
+		deleteDb.Table("cities")
+		deleteDb.Where("id", "=", id, true)
+		res, _ := deleteDb.Delete()
+		if r, _ := res.RowsAffected(); r != 1 {
+			t.Fatalf("Should have deleted 1 record, actually deleted %d", r)
+		}
+	}
+}
+
+func TestPostgreSQLInsertMultiAndDelete(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.InsertMulti([]string{
+		"city",
+	}, [][]interface{}{
+		{"Hartlepool"},
+		{"Liverpool"},
+		{"Blackpool"},
+	}, true)
+	res, err := db.Save()
+	if err != nil {
+		t.Fatalf("Failed inserting multiple rows, got %s", err.Error())
+	}
+	rows, _ := res.RowsAffected()
+	if rows != 3 {
+		t.Fatalf("Expected to insert 3 rows, got %d", rows)
+	}
+	insertId, _ := res.LastInsertId()
+
+	delDb, _ := db.NewQuery()
+	delDb.Table("cities")
+	delDb.Where("id", ">=", insertId, true)
+	delDb.Where("id", "<", insertId+rows, true)
+	delRes, err := delDb.Delete()
+	if err != nil {
+		t.Fatalf("Failed deleting multiple rows, got %s", err.Error())
+	}
+	rows, _ = delRes.RowsAffected()
+	if rows != 3 {
+		t.Fatalf("Expected to delete 3 rows, got %d", rows)
+	}
+
+}
+
+func TestPostgreSQLUpdate(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("party_guests")
+	db.Update(map[string]interface{}{
+		"accepted": 1,
+	}, true)
+	db.Where("user_id", "=", 1, true)
+	db.Where("party_id", "=", 1, true)
+	res, _ := db.Save()
+	if r, _ := res.RowsAffected(); r != 1 {
+		t.Fatalf("Should have updated 1 record, actually updated %d", r)
+	}
+}
+
+func TestPostgreSQLStandardJoin(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"users.city_id",
+		"cities.id",
+	})
+	db.JoinTable("cities", "cities.id", "users.city_id")
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	rowNum := 0
+	for res.Next() {
+		rowNum++
+		var (
+			city_id int32
+			id      int32
+		)
+		res.Scan(&city_id, &id)
+		if city_id != id {
+			t.Fatalf("Joined table IDs do not match, got %d and %d", city_id, id)
+		}
+	}
+	if rowNum == 0 {
+		t.Fatalf("No rows found")
+	}
+}
+
+func TestPostgreSQLStandardLeftJoin(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"users.country_id",
+		"countries.id",
+	})
+	db.LeftJoinTable("countries", "countries.id", "users.country_id")
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	rowNum := 0
+	foundNull := false
+	for res.Next() {
+		rowNum++
+		var (
+			country_id sql.NullInt32
+			id         sql.NullInt32
+		)
+		res.Scan(&country_id, &id)
+		if country_id.Valid && !id.Valid {
+			t.Fatalf("Found records where id's don't match, found %d and %d", country_id.Int32, id.Int32)
+		}
+		if !id.Valid {
+			foundNull = true
+		}
+	}
+	if !foundNull {
+		t.Fatalf("Did not find record with a null country_id")
+	}
+	if rowNum == 0 {
+		t.Fatalf("No rows found")
+	}
+}
+
+func TestPostgreSQLQueryJoin(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"users.id",
+		"users.first_name",
+		"party_guests.accepted",
+		"party_guests.party_id",
+	})
+	db.JoinTableQuery("party_guests", func(q *Query) {
+		q.On("users.id", "=", "party_guests.user_id", false)
+		q.On("party_guests.accepted", "=", 1, true)
+	})
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	rowNum := 0
+
+	for res.Next() {
+		rowNum++
+		var (
+			id         int32
+			first_name string
+			accepted   bool
+			party_id   int32
+		)
+		res.Scan(&id, &first_name, &accepted, &party_id)
+		if !accepted {
+			t.Fatalf("Found unaccepted party guest, user %d, party %d", id, party_id)
+		}
+	}
+	if rowNum == 0 {
+		t.Fatalf("No rows found")
+	}
+}
+
+func TestPostgreSQLSubJoin(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"users.id",
+		"users.first_name",
+		"g.accepted",
+	})
+	subDb, _ := db.NewQuery()
+	subDb.Table("party_guests")
+	subDb.Cols([]string{
+		"user_id",
+		"accepted",
+	})
+
+	db.JoinSub(subDb, "g", "users.id", "g.user_id")
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	rowNum := 0
+
+	for res.Next() {
+		rowNum++
+		var (
+			id         int32
+			first_name string
+			accepted   bool
+		)
+		res.Scan(&id, &first_name, &accepted)
+		if id == 0 {
+			t.Fatalf("Expected non-zero id, got %d", id)
+		}
+	}
+	if rowNum == 0 {
+		t.Fatalf("No rows found")
+	}
+}
+
+func TestPostgreSQLOrdering(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"first_name",
+	})
+	db.OrderBy("first_name", "ASC")
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	prevStr := ""
+	for res.Next() {
+		var first_name string
+		res.Scan(&first_name)
+		if first_name < prevStr {
+			t.Fatalf("String should be lower than previous, got %s and %s", first_name, prevStr)
+		}
+		prevStr = first_name
+	}
+}
+
+func TestPostgreSQLGrouping(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("party_guests")
+	db.Cols([]string{
+		db.Count("id", "number"),
+		"user_id",
+	})
+	db.GroupBy("user_id")
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	for res.Next() {
+		var (
+			number  int32
+			user_id int32
+		)
+		res.Scan(&number, &user_id)
+	}
+}
+
+func TestPostgreSQLLimitOffset(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+	})
+	db.OrderBy("id", "ASC")
+	db.LimitBy(1)
+	res, closeFunc, _ := db.Fetch()
+	defer closeFunc()
+	numRows := 0
+	for res.Next() {
+		numRows++
+		var (
+			id int
+		)
+		res.Scan(&id)
+		if id != 1 {
+			t.Fatalf("Expected ID 1, got %d", id)
+		}
+	}
+	if numRows != 1 {
+		t.Fatalf("Expected 1 row, got %d", numRows)
+	}
+
+	db.LimitBy(2)
+	db.OffsetBy(1)
+
+	res, closeFunc, _ = db.Fetch()
+	defer closeFunc()
+	numRows = 0
+	ids := []string{}
+	for res.Next() {
+		numRows++
+		var (
+			id int
+		)
+		res.Scan(&id)
+		ids = append(ids, fmt.Sprintf("%d", id))
+	}
+	if numRows != 2 {
+		t.Fatalf("Expected 2 rows, got %d", numRows)
+	}
+	idStr := strings.Join(ids, ",")
+	if idStr != "2,3" {
+		t.Fatalf("Expected IDs 2 and 3, got %s", idStr)
+	}
+
+}
+
+func TestPostgreSQLConcurrentFetch(t *testing.T) {
+	db, _ := Open("postgres_test")
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+	})
+	successChannel, startRowsChannel, rowChannel, nextChannel, completeChannel, _, errorChannel := db.FetchConcurrent()
+	select {
+	case err := <-errorChannel:
+		t.Fatalf("Concurrent fetch errored with %s", err.Error())
+	case <-successChannel:
+		startRowsChannel <- true
+		numRows := 0
+		complete := false
+		for {
+			select {
+			case <-rowChannel:
+				numRows++
+				nextChannel <- true
+			case <-completeChannel:
+				complete = true
+			}
+			if complete {
+				break
+			}
+		}
+		if numRows == 0 {
+			t.Fatalf("No rows returned")
+		}
+	}
+}
+
+func TestPostgreSQLConcurrentMultiFetch(t *testing.T) {
+	db1, _ := Open("postgres_test")
+	db1.Table("users")
+	db1.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+	})
+
+	db2, _ := db1.NewQuery()
+	db2.Table("cities")
+	db2.Cols([]string{
+		"id",
+		"city",
+	})
+
+	db3, _ := db1.NewQuery()
+	db3.Table("genders")
+	db3.Cols([]string{
+		"id",
+		"gender",
+	})
+
+	db4, _ := db1.NewQuery()
+	db4.Table("countries")
+	db4.Cols([]string{
+		"id",
+		"country",
+	})
+
+	results := ConcurrentFetch(db1, db2, db3, db4)
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 sets of results, got %d", len(results))
+	}
+	for i, res := range results {
+		if i == 0 {
+			numRows := 0
+			if len(res.Errors) > 0 {
+				t.Fatalf("First query errored %s", joinErrors(res.Errors))
+			}
+			complete := false
+			res.StartRowsChannel <- true
+			for {
+				select {
+				case row := <-res.RowChannel:
+					numRows++
+					var (
+						id         int32
+						first_name string
+						surname    string
+					)
+					row.Scan(&id, &first_name, &surname)
+					res.NextChannel <- true
+				case <-res.CompleteChannel:
+					complete = true
+				}
+				if complete {
+					break
+				}
+			}
+			if numRows == 0 {
+				t.Fatal("No results returned in first concurrent query")
+			}
+		} else if i == 1 {
+			numRows := 0
+			if len(res.Errors) > 0 {
+				t.Fatalf("Second query errored %s", joinErrors(res.Errors))
+			}
+			complete := false
+			res.StartRowsChannel <- true
+			for {
+				select {
+				case row := <-res.RowChannel:
+					numRows++
+					var (
+						id   int32
+						city string
+					)
+					row.Scan(&id, &city)
+					res.NextChannel <- true
+				case <-res.CompleteChannel:
+					complete = true
+				}
+				if complete {
+					break
+				}
+			}
+			if numRows == 0 {
+				t.Fatal("No results returned in second concurrent query")
+			}
+		} else if i == 2 {
+			numRows := 0
+			if len(res.Errors) > 0 {
+				t.Fatalf("Third query errored %s", joinErrors(res.Errors))
+			}
+			complete := false
+			res.StartRowsChannel <- true
+			for {
+				select {
+				case row := <-res.RowChannel:
+					numRows++
+					var (
+						id     int32
+						gender string
+					)
+					row.Scan(&id, &gender)
+					res.NextChannel <- true
+				case <-res.CompleteChannel:
+					complete = true
+				}
+				if complete {
+					break
+				}
+			}
+			if numRows == 0 {
+				t.Fatal("No results returned in third concurrent query")
+			}
+		} else if i == 3 {
+			if len(res.Errors) > 0 {
+				t.Fatalf("Third query errored %s", joinErrors(res.Errors))
+			}
+			res.CancelChannel <- true
+		}
+	}
+}
+
+func runPostgreSQLConcurrent() {
+
+	queries := []DB{}
+	for i := 0; i < 20; i++ {
+		db, _ := Open("postgres_test")
+		db.Table("users")
+		db.Cols([]string{
+			"id",
+			"first_name",
+			"surname",
+		})
+		queries = append(queries, db)
+	}
+
+	res := ConcurrentFetch(queries...)
+	for _, r := range res {
+		if len(r.Errors) == 0 {
+			r.StartRowsChannel <- true
+			done := false
+			for {
+				select {
+				case row := <-r.RowChannel:
+					var (
+						id         int
+						first_name string
+						surname    string
+					)
+					row.Scan(&id, &first_name, &surname)
+					r.NextChannel <- true
+				case <-r.CompleteChannel:
+					done = true
+				}
+				if done {
+					break
+				}
+			}
+		} else {
+			fmt.Println(r.Errors)
+			fmt.Println("oh no")
+		}
+	}
+}
+
+func runPostgreSQLNonConcurrent() {
+	for i := 0; i < 20; i++ {
+		db, _ := Open("postgres_test")
+		db.Table("users")
+		db.Cols([]string{
+			"id",
+			"first_name",
+			"surname",
+		})
+		_, c, _ := db.Fetch()
+		defer c()
+	}
+}
+
+func BenchmarkPostgreSQLConc(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		runPostgreSQLConcurrent()
+	}
+}
+
+func BenchmarkPostgreSQLNonConc(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		runPostgreSQLNonConcurrent()
+	}
+}
+
+func TestPostgreSQLFetchContext(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+	})
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := db.FetchContext(cancelledCtx); err == nil {
+		t.Fatal("Expected query with a cancelled context to fail")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, close, err := db.FetchContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		rowNum++
+	}
+	if rowNum != 4 {
+		t.Fatalf("Failed fetching rows, expected 4 got %d", rowNum)
+	}
+}
+
+func TestPostgreSQLSaveAndDeleteContext(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db.Table("cities")
+	db.Insert(map[string]interface{}{
+		"city": "Matlock",
+	}, true)
+	res, err := db.SaveContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed inserting row, got %s", err.Error())
+	}
+	id, _ := res.LastInsertId()
+
+	deleteDb, _ := db.NewQuery()
+	deleteDb.Table("cities")
+	deleteDb.Where("id", "=", id, true)
+	res, err = deleteDb.DeleteContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed deleting row, got %s", err.Error())
+	}
+	if r, _ := res.RowsAffected(); r != 1 {
+		t.Fatalf("Should have deleted 1 record, actually deleted %d", r)
+	}
+}
+
+func countPostgreSQLCities(t *testing.T, city string) int {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.Cols([]string{
+		db.Count("id", "num"),
+	})
+	db.Where("city", "=", city, true)
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	num := 0
+	for res.Next() {
+		res.Scan(&num)
+	}
+	return num
+}
+
+func deletePostgreSQLCities(cities ...string) {
+	db, _ := Open("postgres_test")
+	values := []interface{}{}
+	for _, city := range cities {
+		values = append(values, city)
+	}
+	db.Table("cities")
+	db.WhereInList("city", values, true)
+	db.Delete()
+}
+
+func TestPostgreSQLTransactionCommit(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Committed One", "Committed Two")
+	err = db.Transaction(func(tx Tx) error {
+		insertDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		insertDb.Table("cities")
+		insertDb.InsertMulti([]string{
+			"city",
+		}, [][]interface{}{
+			{"Committed One"},
+			{"Committed Two"},
+		}, true)
+		_, err = insertDb.Save()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed running transaction, got %s", err.Error())
+	}
+	if num := countPostgreSQLCities(t, "Committed One") + countPostgreSQLCities(t, "Committed Two"); num != 2 {
+		t.Fatalf("Expected 2 committed rows, got %d", num)
+	}
+}
+
+func TestPostgreSQLTransactionRollback(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Rolled Back")
+	rollbackErr := errors.New("roll back")
+	err = db.Transaction(func(tx Tx) error {
+		insertDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		insertDb.Table("cities")
+		insertDb.Insert(map[string]interface{}{
+			"city": "Rolled Back",
+		}, true)
+		if _, err := insertDb.Save(); err != nil {
+			return err
+		}
+		return rollbackErr
+	})
+	if !errors.Is(err, rollbackErr) {
+		t.Fatalf("Expected the transaction error to be returned, got %v", err)
+	}
+	if num := countPostgreSQLCities(t, "Rolled Back"); num != 0 {
+		t.Fatalf("Expected inserted row to be rolled back, found %d", num)
+	}
+}
+
+func TestPostgreSQLTransactionPanic(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Panicked")
+	func() {
+		defer func() {
+			if p := recover(); p == nil {
+				t.Fatal("Expected the panic to be passed on")
+			}
+		}()
+		db.Transaction(func(tx Tx) error {
+			insertDb, _ := tx.NewQuery()
+			insertDb.Table("cities")
+			insertDb.Insert(map[string]interface{}{
+				"city": "Panicked",
+			}, true)
+			insertDb.Save()
+			panic("transaction panic")
+		})
+	}()
+	if num := countPostgreSQLCities(t, "Panicked"); num != 0 {
+		t.Fatalf("Expected inserted row to be rolled back, found %d", num)
+	}
+}
+
+func TestPostgreSQLTransactionSavepoint(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Kept", "Discarded")
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Failed starting transaction, got %s", err.Error())
+	}
+	keptDb, _ := tx.NewQuery()
+	keptDb.Table("cities")
+	keptDb.Insert(map[string]interface{}{
+		"city": "Kept",
+	}, true)
+	if _, err := keptDb.Save(); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed inserting row, got %s", err.Error())
+	}
+	if err := tx.Savepoint("before_discard"); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed creating savepoint, got %s", err.Error())
+	}
+	discardedDb, _ := tx.NewQuery()
+	discardedDb.Table("cities")
+	discardedDb.Insert(map[string]interface{}{
+		"city": "Discarded",
+	}, true)
+	if _, err := discardedDb.Save(); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed inserting row, got %s", err.Error())
+	}
+	if err := tx.RollbackTo("before_discard"); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed rolling back to savepoint, got %s", err.Error())
+	}
+	if err := tx.Savepoint("bad name; DROP TABLE cities"); err == nil {
+		tx.Rollback()
+		t.Fatal("Expected invalid savepoint name to be rejected")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed committing transaction, got %s", err.Error())
+	}
+	if num := countPostgreSQLCities(t, "Kept"); num != 1 {
+		t.Fatalf("Expected 1 kept row, got %d", num)
+	}
+	if num := countPostgreSQLCities(t, "Discarded"); num != 0 {
+		t.Fatalf("Expected discarded row to be rolled back, got %d", num)
+	}
+}
+
+func TestPostgreSQLTransactionRetry(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Retried")
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+	calls := 0
+	attempts, err := db.TransactionRetry(context.Background(), policy, func(tx Tx) error {
+		calls++
+		insertDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		insertDb.Table("cities")
+		insertDb.Insert(map[string]interface{}{
+			"city": "Retried",
+		}, true)
+		if _, err := insertDb.Save(); err != nil {
+			return err
+		}
+		if calls == 1 {
+			return &pq.Error{Code: "40P01", Message: "deadlock detected"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed running transaction, got %s", err.Error())
+	}
+	if attempts != 2 || calls != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", attempts)
+	}
+	if num := countPostgreSQLCities(t, "Retried"); num != 1 {
+		t.Fatalf("Expected only the retried insert to be committed, found %d", num)
+	}
+}
+
+func TestPostgreSQLTransactionRetryGivesUp(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+	attempts, err := db.TransactionRetry(context.Background(), policy, func(tx Tx) error {
+		return &pq.Error{Code: "40P01", Message: "deadlock detected"}
+	})
+	if err == nil {
+		t.Fatal("Expected the deadlock error to be returned")
+	}
+	if attempts != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", attempts)
+	}
+	otherErr := errors.New("not a deadlock")
+	attempts, err = db.TransactionRetry(context.Background(), policy, func(tx Tx) error {
+		return otherErr
+	})
+	if !errors.Is(err, otherErr) {
+		t.Fatalf("Expected the transaction error to be returned, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("Expected errors that aren't deadlocks not to be retried, got %d attempts", attempts)
+	}
+}
+
+type scanPostgreSQLName struct {
+	FirstName string
+	Surname   string `db:"surname"`
+}
+
+type scanPostgreSQLUser struct {
+	scanPostgreSQLName
+	ID        int64          `db:"id"`
+	Email     sql.NullString `db:"email"`
+	CountryID *int64
+	Ignored   string `db:"-"`
+}
+
+func TestPostgreSQLFetchInto(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+		"email",
+		"country_id",
+	})
+	db.WhereInList("first_name", []interface{}{"Steve", "Bob"}, true)
+	db.OrderBy("first_name", "ASC")
+	users := []scanPostgreSQLUser{}
+	if err := db.FetchInto(&users); err != nil {
+		t.Fatalf("Failed fetching into struct, got %s", err.Error())
+	}
+	if len(users) != 2 {
+		t.Fatalf("Expected 2 users, got %d", len(users))
+	}
+	bob, steve := users[0], users[1]
+	if bob.FirstName != "Bob" || bob.Surname != "Briar" || bob.ID == 0 {
+		t.Fatalf("Expected Bob Briar, got %+v", bob)
+	}
+	if bob.Email.Valid || bob.CountryID != nil {
+		t.Fatalf("Expected null email and country for Bob, got %+v", bob)
+	}
+	if steve.Email.String != "ste@ber.com" || steve.CountryID == nil || *steve.CountryID != 1 {
+		t.Fatalf("Expected email and country for Steve, got %+v", steve)
+	}
+
+	pointers := []*scanPostgreSQLUser{}
+	if err := db.FetchInto(&pointers); err != nil {
+		t.Fatalf("Failed fetching into struct pointers, got %s", err.Error())
+	}
+	if len(pointers) != 2 || pointers[1].FirstName != "Steve" {
+		t.Fatalf("Expected 2 users ending with Steve, got %d", len(pointers))
+	}
+}
+
+func TestPostgreSQLFirstInto(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+	})
+	db.Where("first_name", "=", "Sharon", true)
+	user := scanPostgreSQLUser{}
+	if err := db.FirstInto(&user); err != nil {
+		t.Fatalf("Failed fetching first into struct, got %s", err.Error())
+	}
+	if user.FirstName != "Sharon" || user.Surname != "Pollard" {
+		t.Fatalf("Expected Sharon Pollard, got %+v", user)
+	}
+
+	missingDb, _ := db.NewQuery()
+	missingDb.Table("users")
+	missingDb.Cols([]string{
+		"id",
+	})
+	missingDb.Where("first_name", "=", "Nobody", true)
+	if err := missingDb.FirstInto(&user); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestPostgreSQLFetchIntoUnmappedColumn(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"postcode",
+	})
+	users := []scanPostgreSQLUser{}
+	err = db.FetchInto(&users)
+	if err == nil || !strings.Contains(err.Error(), "postcode") {
+		t.Fatalf("Expected an error naming the postcode column, got %v", err)
+	}
+}
+
+func TestPostgreSQLTypedSelect(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+	})
+	db.Where("surname", "=", "Jones", true)
+	users, err := Select[scanPostgreSQLUser](db)
+	if err != nil {
+		t.Fatalf("Failed selecting users, got %s", err.Error())
+	}
+	if len(users) != 1 || users[0].FirstName != "Juliet" {
+		t.Fatalf("Expected Juliet Jones, got %+v", users)
+	}
+
+	_, err = First[*scanPostgreSQLUser](db)
+	if err == nil {
+		t.Fatal("Expected an error using First with a pointer type")
+	}
+	first, err := First[scanPostgreSQLUser](db)
+	if err != nil {
+		t.Fatalf("Failed selecting first user, got %s", err.Error())
+	}
+	if first.FirstName != "Juliet" {
+		t.Fatalf("Expected Juliet, got %+v", first)
+	}
+}
+
+func TestPostgreSQLTypedRows(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+	})
+	db.OrderBy("id", "ASC")
+	rows, err := FetchRows[*scanPostgreSQLUser](db)
+	if err != nil {
+		t.Fatalf("Failed fetching rows, got %s", err.Error())
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		names = append(names, rows.Value().FirstName)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Failed iterating rows, got %s", err.Error())
+	}
+	if strings.Join(names, ",") != "Steve,Bob,Sharon,Juliet" {
+		t.Fatalf("Expected all 4 users in order, got %v", names)
+	}
+}
+
+func TestPostgreSQLFetchMaps(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"first_name",
+		"email",
+		"country_id",
+		"date_of_birth",
+	})
+	db.WhereInList("first_name", []interface{}{"Steve", "Bob"}, true)
+	db.OrderBy("first_name", "ASC")
+	rows, err := db.FetchMaps()
+	if err != nil {
+		t.Fatalf("Failed fetching maps, got %s", err.Error())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	bob, steve := rows[0], rows[1]
+	if bob["first_name"] != "Bob" || bob["email"] != nil || bob["country_id"] != nil {
+		t.Fatalf("Expected Bob with null email and country, got %v", bob)
+	}
+	if steve["email"] != "ste@ber.com" {
+		t.Fatalf("Expected email to be a string, got %#v", steve["email"])
+	}
+	if countryId, ok := steve["country_id"].(int64); !ok || countryId != 1 {
+		t.Fatalf("Expected country_id to be an int64, got %#v", steve["country_id"])
+	}
+	dateOfBirth, ok := steve["date_of_birth"].(time.Time)
+	if !ok || dateOfBirth.Year() != 1993 || dateOfBirth.Month() != time.July {
+		t.Fatalf("Expected date_of_birth to be a time, got %#v", steve["date_of_birth"])
+	}
+}
+
+func TestPostgreSQLColumns(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"email",
+	})
+	columns, err := db.Columns()
+	if err != nil {
+		t.Fatalf("Failed getting columns, got %s", err.Error())
+	}
+	if len(columns) != 3 {
+		t.Fatalf("Expected 3 columns, got %d", len(columns))
+	}
+	for i, name := range []string{"id", "first_name", "email"} {
+		if columns[i].Name != name || columns[i].DatabaseType == "" {
+			t.Fatalf("Expected column %s with a database type, got %+v", name, columns[i])
+		}
+	}
+}
+
+type structPostgreSQLCity struct {
+	ID   int64  `db:"id,pk"`
+	City string `db:"city"`
+}
+
+func TestPostgreSQLInsertAndUpdateStruct(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Struct City", "Updated Struct City")
+	city := structPostgreSQLCity{
+		City: "Struct City",
+	}
+	db.Table("cities")
+	if err := db.InsertStruct(&city); err != nil {
+		t.Fatalf("Failed setting insert from struct, got %s", err.Error())
+	}
+	res, err := db.Save()
+	if err != nil {
+		t.Fatalf("Failed inserting struct, got %s", err.Error())
+	}
+	lastId, _ := res.LastInsertId()
+	if city.ID == 0 || city.ID != lastId {
+		t.Fatalf("Expected the struct id to be set to %d, got %d", lastId, city.ID)
+	}
+
+	city.City = "Updated Struct City"
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("cities")
+	if err := updateDb.UpdateStruct(&city, "city"); err != nil {
+		t.Fatalf("Failed setting update from struct, got %s", err.Error())
+	}
+	if _, err := updateDb.Save(); err != nil {
+		t.Fatalf("Failed updating struct, got %s", err.Error())
+	}
+	if num := countPostgreSQLCities(t, "Updated Struct City"); num != 1 {
+		t.Fatalf("Expected the city to be updated, found %d", num)
+	}
+
+	invalidDb, _ := db.NewQuery()
+	invalidDb.Table("cities")
+	if err := invalidDb.UpdateStruct(&city, "population"); err == nil {
+		t.Fatal("Expected an error updating a column the struct doesn't have")
+	}
+}
+
+func TestPostgreSQLInsertStructs(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Struct One", "Struct Two")
+	cities := []structPostgreSQLCity{
+		{City: "Struct One"},
+		{City: "Struct Two"},
+	}
+	db.Table("cities")
+	if err := db.InsertStructs(cities); err != nil {
+		t.Fatalf("Failed setting insert from structs, got %s", err.Error())
+	}
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting structs, got %s", err.Error())
+	}
+	if cities[0].ID == 0 || cities[1].ID != cities[0].ID+1 {
+		t.Fatalf("Expected sequential ids to be set, got %d and %d", cities[0].ID, cities[1].ID)
+	}
+	if num := countPostgreSQLCities(t, "Struct One") + countPostgreSQLCities(t, "Struct Two"); num != 2 {
+		t.Fatalf("Expected 2 inserted cities, found %d", num)
+	}
+}
+
+func TestPostgreSQLInsertColumnOrder(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	values := map[string]interface{}{
+		"surname":    "Smith",
+		"first_name": "John",
+		"email":      "john@smith.com",
+	}
+	db.Table("users")
+	db.Insert(values, true)
+	query := db.GenerateInsert()
+	if !strings.Contains(query, "(email,first_name,surname)") {
+		t.Fatalf("Expected insert columns in alphabetical order, got %s", query)
+	}
+	for i := 0; i < 10; i++ {
+		repeatDb, _ := db.NewQuery()
+		repeatDb.Table("users")
+		repeatDb.Insert(values, true)
+		if repeated := repeatDb.GenerateInsert(); repeated != query {
+			t.Fatalf("Expected the same insert query each time, got %s and %s", query, repeated)
+		}
+	}
+
+	orderedDb, _ := db.NewQuery()
+	orderedDb.Table("users")
+	orderedDb.InsertOrdered([]string{
+		"surname",
+		"first_name",
+	}, []interface{}{
+		"Smith",
+		"John",
+	}, true)
+	if query := orderedDb.GenerateInsert(); !strings.Contains(query, "(surname,first_name)") {
+		t.Fatalf("Expected insert columns in the given order, got %s", query)
+	}
+}
+
+func TestPostgreSQLUpdateColumnOrder(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Update(map[string]interface{}{
+		"surname":    "Smith",
+		"first_name": "John",
+		"email":      "john@smith.com",
+	}, true)
+	query := db.GenerateUpdate()
+	email, firstName, surname := strings.Index(query, "email ="), strings.Index(query, "first_name ="), strings.Index(query, "surname =")
+	if email < 0 || !(email < firstName && firstName < surname) {
+		t.Fatalf("Expected update columns in alphabetical order, got %s", query)
+	}
+
+	orderedDb, _ := db.NewQuery()
+	orderedDb.Table("users")
+	orderedDb.UpdateOrdered([]string{
+		"surname",
+		"first_name",
+	}, []interface{}{
+		"Smith",
+		"John",
+	}, true)
+	query = orderedDb.GenerateUpdate()
+	if strings.Index(query, "surname =") > strings.Index(query, "first_name =") {
+		t.Fatalf("Expected update columns in the given order, got %s", query)
+	}
+}
+
+func fetchPostgreSQLPostcodes(t *testing.T, db DB) []string {
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching grouped results, got %s", err.Error())
+	}
+	defer close()
+	postcodes := []string{}
+	for res.Next() {
+		var (
+			postcode string
+			num      int
+		)
+		res.Scan(&postcode, &num)
+		postcodes = append(postcodes, postcode)
+	}
+	return postcodes
+}
+
+func TestPostgreSQLHaving(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"postcode",
+		db.Count("*", "num"),
+	})
+	db.Where("title_id", "=", 1, true)
+	db.GroupBy("postcode")
+	db.Having("COUNT(*)", ">", 1, true)
+	db.OrderBy("postcode", "ASC")
+	if postcodes := fetchPostgreSQLPostcodes(t, db); strings.Join(postcodes, ",") != "DE71 AXC" {
+		t.Fatalf("Expected only DE71 AXC to have more than one user, got %v", postcodes)
+	}
+
+	orDb, _ := db.Clone()
+	orDb.OrHaving("postcode", "=", "ST54 POC", true)
+	if postcodes := fetchPostgreSQLPostcodes(t, orDb); strings.Join(postcodes, ",") != "DE71 AXC,ST54 POC" {
+		t.Fatalf("Expected DE71 AXC and ST54 POC, got %v", postcodes)
+	}
+}
+
+func TestPostgreSQLHavingBrackets(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"postcode",
+		db.Count("*", "num"),
+	})
+	db.GroupBy("postcode")
+	db.Having("COUNT(*)", ">", 5, true)
+	db.OrOpenHavingBracket()
+	db.HavingIn("postcode", []interface{}{"DE76 YAS", "DE71 AXC"}, true)
+	db.Having("COUNT(*)", "=", 1, true)
+	db.CloseHavingBracket()
+	db.OrderBy("postcode", "ASC")
+	if postcodes := fetchPostgreSQLPostcodes(t, db); strings.Join(postcodes, ",") != "DE76 YAS" {
+		t.Fatalf("Expected only DE76 YAS, got %v", postcodes)
+	}
+}
+
+func fetchPostgreSQLFirstNames(t *testing.T, db DB) []string {
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching names, got %s", err.Error())
+	}
+	defer close()
+	names := []string{}
+	for res.Next() {
+		var name string
+		res.Scan(&name)
+		names = append(names, name)
+	}
+	return names
+}
+
+func newPostgreSQLNameQuery(t *testing.T, db DB, field string, values ...interface{}) DB {
+	nameDb, err := db.NewQuery()
+	if err != nil {
+		t.Fatalf("Failed creating query, got %s", err.Error())
+	}
+	nameDb.Table("users")
+	nameDb.Cols([]string{
+		"first_name",
+	})
+	nameDb.WhereInList(field, values, true)
+	return nameDb
+}
+
+func TestPostgreSQLUnion(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	unionDb := newPostgreSQLNameQuery(t, db, "first_name", "Steve")
+	unionDb.Union(newPostgreSQLNameQuery(t, db, "first_name", "Bob", "Steve"))
+	unionDb.OrderBy("first_name", "ASC")
+	if names := fetchPostgreSQLFirstNames(t, unionDb); strings.Join(names, ",") != "Bob,Steve" {
+		t.Fatalf("Expected Bob and Steve once each, got %v", names)
+	}
+
+	unionAllDb := newPostgreSQLNameQuery(t, db, "first_name", "Steve")
+	unionAllDb.UnionAll(newPostgreSQLNameQuery(t, db, "first_name", "Bob", "Steve"))
+	unionAllDb.OrderBy("first_name", "ASC")
+	if names := fetchPostgreSQLFirstNames(t, unionAllDb); strings.Join(names, ",") != "Bob,Steve,Steve" {
+		t.Fatalf("Expected Steve to be included twice, got %v", names)
+	}
+}
+
+func TestPostgreSQLIntersectAndExcept(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	intersectDb := newPostgreSQLNameQuery(t, db, "surname", "Briar", "Jones")
+	intersectDb.Intersect(newPostgreSQLNameQuery(t, db, "first_name", "Bob", "Steve"))
+	if names := fetchPostgreSQLFirstNames(t, intersectDb); strings.Join(names, ",") != "Bob" {
+		t.Fatalf("Expected only Bob, got %v", names)
+	}
+
+	exceptDb := newPostgreSQLNameQuery(t, db, "first_name", "Bob", "Sharon", "Steve")
+	exceptDb.Except(newPostgreSQLNameQuery(t, db, "first_name", "Bob"))
+	exceptDb.OrderBy("first_name", "DESC")
+	if names := fetchPostgreSQLFirstNames(t, exceptDb); strings.Join(names, ",") != "Steve,Sharon" {
+		t.Fatalf("Expected Steve and Sharon, got %v", names)
+	}
+}
+
+func TestPostgreSQLWith(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	activeDb, _ := db.NewQuery()
+	activeDb.Table("users")
+	activeDb.Cols([]string{
+		"first_name",
+		"surname",
+	})
+	activeDb.Where("active", "=", 1, true)
+
+	db.With("active_users", activeDb)
+	db.Table("active_users")
+	db.Cols([]string{
+		"first_name",
+	})
+	db.Where("surname", "!=", "Jones", true)
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected only Steve, got %v", names)
+	}
+}
+
+func TestPostgreSQLWithRecursive(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	anchorDb, _ := db.NewQuery()
+	anchorDb.Table("cities")
+	anchorDb.Cols([]string{
+		"1 n",
+	})
+	anchorDb.Where("city", "=", "Derby", true)
+
+	recursiveDb, _ := db.NewQuery()
+	recursiveDb.Table("numbers")
+	recursiveDb.Cols([]string{
+		"n + 1 n",
+	})
+	recursiveDb.Where("n", "<", 5, true)
+
+	db.WithRecursive("numbers", anchorDb, recursiveDb)
+	db.Table("numbers")
+	db.Cols([]string{
+		"n",
+	})
+	db.Where("n", ">", 2, true)
+	db.OrderBy("n", "ASC")
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching recursive query, got %s", err.Error())
+	}
+	defer close()
+	numbers := []int{}
+	for res.Next() {
+		var n int
+		res.Scan(&n)
+		numbers = append(numbers, n)
+	}
+	if fmt.Sprint(numbers) != "[3 4 5]" {
+		t.Fatalf("Expected 3 to 5, got %v", numbers)
+	}
+}
+
+func TestPostgreSQLUpdateWith(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("With Update", "With Updated")
+	db.Table("cities")
+	db.Insert(map[string]interface{}{
+		"city": "With Update",
+	}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting city, got %s", err.Error())
+	}
+
+	renameDb, _ := db.NewQuery()
+	renameDb.Table("users")
+	renameDb.Cols([]string{
+		"'With Update' city",
+	})
+	renameDb.Where("first_name", "=", "Steve", true)
+
+	cteDb, _ := db.NewQuery()
+	cteDb.Table("renames")
+	cteDb.Cols([]string{
+		"city",
+	})
+
+	updateDb, _ := db.NewQuery()
+	updateDb.With("renames", renameDb)
+	updateDb.Table("cities")
+	updateDb.Update(map[string]interface{}{
+		"city": "With Updated",
+	}, true)
+	updateDb.WhereInSub("city", cteDb)
+	if _, err := updateDb.Save(); err != nil {
+		t.Fatalf("Failed updating with a common table expression, got %s", err.Error())
+	}
+	if num := countPostgreSQLCities(t, "With Updated"); num != 1 {
+		t.Fatalf("Expected the city to be updated, found %d", num)
+	}
+}
+
+func TestPostgreSQLWindowFunctions(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"first_name",
+		db.Window(RowNumber().Over([]string{"postcode"}, []string{"first_name ASC"}, ""), "row_num"),
+		db.Window(Lag("first_name", 1).Over(nil, []string{"first_name"}, ""), "previous_name"),
+		db.Window(Sum("title_id").Over(nil, []string{"first_name"}, FrameRunningTotal), "running_total"),
+		db.Window(Count("*").Over([]string{"postcode"}, nil, ""), "postcode_users"),
+	})
+	db.OrderBy("first_name", "ASC")
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching window functions, got %s", err.Error())
+	}
+	defer close()
+	results := []string{}
+	for res.Next() {
+		var (
+			firstName     string
+			rowNum        int
+			previousName  sql.NullString
+			runningTotal  int
+			postcodeUsers int
+		)
+		res.Scan(&firstName, &rowNum, &previousName, &runningTotal, &postcodeUsers)
+		results = append(results, fmt.Sprintf("%s %d %s %d %d", firstName, rowNum, previousName.String, runningTotal, postcodeUsers))
+	}
+	expected := []string{
+		"Bob 1  1 2",
+		"Juliet 1 Bob 2 1",
+		"Sharon 2 Juliet 3 2",
+		"Steve 1 Sharon 4 1",
+	}
+	if strings.Join(results, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestPostgreSQLWindowWithoutOrder(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		db.Window(RowNumber().Over(nil, nil, ""), "row_num"),
+	})
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching window function without ordering, got %s", err.Error())
+	}
+	defer close()
+	total := 0
+	for res.Next() {
+		var rowNum int
+		res.Scan(&rowNum)
+		total += rowNum
+	}
+	if total != 10 {
+		t.Fatalf("Expected row numbers 1 to 4, got a total of %d", total)
+	}
+}
+
+func TestPostgreSQLCrossJoin(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("genders")
+	db.CrossJoinAs("genders", "other_genders")
+	db.Cols([]string{
+		db.Count("*", "num"),
+	})
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching cross join, got %s", err.Error())
+	}
+	defer close()
+	var num int
+	for res.Next() {
+		res.Scan(&num)
+	}
+	if num != 4 {
+		t.Fatalf("Expected every gender joined to every gender, got %d rows", num)
+	}
+}
+
+func TestPostgreSQLRightJoinAs(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("genders")
+	db.RightJoinTableAs("users", "u", "u.gender_id", "genders.id")
+	db.Cols([]string{
+		"u.first_name",
+	})
+	db.OrderBy("u.first_name", "ASC")
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Bob,Juliet,Sharon,Steve" {
+		t.Fatalf("Expected every user, got %v", names)
+	}
+}
+
+func TestPostgreSQLFullJoinAs(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	insertDb, _ := db.NewQuery()
+	insertDb.Table("genders")
+	insertDb.Insert(map[string]interface{}{
+		"gender": "Other",
+	}, true)
+	if _, err := insertDb.Save(); err != nil {
+		t.Fatalf("Failed inserting gender, got %s", err.Error())
+	}
+	defer func() {
+		deleteDb, _ := db.NewQuery()
+		deleteDb.Table("genders")
+		deleteDb.Where("gender", "=", "Other", true)
+		deleteDb.Delete()
+	}()
+
+	db.Table("users")
+	db.FullJoinTableAs("genders", "g", "g.id", "users.gender_id")
+	db.Cols([]string{
+		"users.first_name",
+		"g.gender",
+	})
+	db.Where("g.gender", "!=", "Male", true)
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching full join, got %s", err.Error())
+	}
+	defer close()
+	rows := []string{}
+	for res.Next() {
+		var (
+			firstName sql.NullString
+			gender    sql.NullString
+		)
+		res.Scan(&firstName, &gender)
+		rows = append(rows, firstName.String+" "+gender.String)
+	}
+	sort.Strings(rows)
+	if strings.Join(rows, ",") != " Other,Juliet Female,Sharon Female" {
+		t.Fatalf("Expected the users and the gender without users, got %v", rows)
+	}
+}
+
+func TestPostgreSQLCrossAndOuterApply(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	firstUserDb, _ := db.NewQuery()
+	firstUserDb.SetParamPrefix("firstUser")
+	firstUserDb.Table("users")
+	firstUserDb.Cols([]string{
+		"first_name",
+	})
+	firstUserDb.Where("users.gender_id", "=", "genders.id", false)
+	firstUserDb.Where("users.active", "=", 1, true)
+	firstUserDb.OrderBy("first_name", "ASC")
+	firstUserDb.LimitBy(1)
+
+	db.Table("genders")
+	db.CrossApply(firstUserDb, "first_user")
+	db.Cols([]string{
+		"first_user.first_name",
+	})
+	db.OrderBy("genders.gender", "ASC")
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Juliet,Steve" {
+		t.Fatalf("Expected the first active user of each gender, got %v", names)
+	}
+
+	outerDb, _ := db.NewQuery()
+	outerDb.Table("genders")
+	outerDb.OuterApply(firstUserDb, "first_user")
+	outerDb.Cols([]string{
+		"genders.gender",
+	})
+	outerDb.Where("genders.gender", "=", "Male", true)
+	if names := fetchPostgreSQLFirstNames(t, outerDb); strings.Join(names, ",") != "Male" {
+		t.Fatalf("Expected the gender to be kept, got %v", names)
+	}
+}
+
+func newPostgreSQLUserNamesQuery(t *testing.T) DB {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"first_name",
+	})
+	db.OrderBy("first_name", "ASC")
+	return db
+}
+
+func TestPostgreSQLWhereBetween(t *testing.T) {
+	db := newPostgreSQLUserNamesQuery(t)
+	db.WhereBetween("date_of_birth", "1980-01-01", "1995-12-31")
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Juliet,Steve" {
+		t.Fatalf("Expected Juliet and Steve, got %v", names)
+	}
+
+	notDb := newPostgreSQLUserNamesQuery(t)
+	notDb.WhereNotBetween("date_of_birth", "1980-01-01", "1995-12-31")
+	if names := fetchPostgreSQLFirstNames(t, notDb); strings.Join(names, ",") != "Bob,Sharon" {
+		t.Fatalf("Expected Bob and Sharon, got %v", names)
+	}
+}
+
+func TestPostgreSQLWhereLike(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("100%_Real", "100 Real")
+	db.Table("cities")
+	db.InsertMulti([]string{
+		"city",
+	}, [][]interface{}{
+		{"100%_Real"},
+		{"100 Real"},
+	}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting cities, got %s", err.Error())
+	}
+
+	likeDb, _ := db.NewQuery()
+	likeDb.Table("cities")
+	likeDb.Cols([]string{
+		"city",
+	})
+	likeDb.WhereLike("city", EscapeLike("100%_")+"%")
+	if cities := fetchPostgreSQLFirstNames(t, likeDb); strings.Join(cities, ",") != "100%_Real" {
+		t.Fatalf("Expected the wildcards to be matched literally, got %v", cities)
+	}
+
+	iLikeDb := newPostgreSQLUserNamesQuery(t)
+	iLikeDb.WhereILike("first_name", "ST%")
+	if names := fetchPostgreSQLFirstNames(t, iLikeDb); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected Steve, got %v", names)
+	}
+}
+
+func TestPostgreSQLWhereExists(t *testing.T) {
+	countryDb, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	countryDb.SetParamPrefix("countrySub")
+	countryDb.Table("countries")
+	countryDb.Cols([]string{
+		"id",
+	})
+	countryDb.WhereColumn("countries.id", "=", "users.country_id")
+	countryDb.Where("countries.country", "=", "United Kingdom", true)
+
+	db := newPostgreSQLUserNamesQuery(t)
+	db.WhereExists(countryDb)
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Juliet,Sharon,Steve" {
+		t.Fatalf("Expected the users with a country, got %v", names)
+	}
+
+	notDb := newPostgreSQLUserNamesQuery(t)
+	notDb.WhereNotExists(countryDb)
+	if names := fetchPostgreSQLFirstNames(t, notDb); strings.Join(names, ",") != "Bob" {
+		t.Fatalf("Expected Bob, got %v", names)
+	}
+}
+
+func TestPostgreSQLWhereColumn(t *testing.T) {
+	db := newPostgreSQLUserNamesQuery(t)
+	db.WhereColumn("gender_id", "=", "title_id")
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Bob,Steve" {
+		t.Fatalf("Expected Bob and Steve, got %v", names)
+	}
+}
+
+func TestPostgreSQLWhereDateParts(t *testing.T) {
+	db := newPostgreSQLUserNamesQuery(t)
+	db.WhereDate("date_of_birth", "=", time.Date(1993, time.July, 12, 0, 0, 0, 0, time.UTC))
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected Steve, got %v", names)
+	}
+
+	yearDb := newPostgreSQLUserNamesQuery(t)
+	yearDb.WhereYear("date_of_birth", ">=", 1990)
+	if names := fetchPostgreSQLFirstNames(t, yearDb); strings.Join(names, ",") != "Bob,Steve" {
+		t.Fatalf("Expected Bob and Steve, got %v", names)
+	}
+
+	monthDb := newPostgreSQLUserNamesQuery(t)
+	monthDb.WhereMonth("date_of_birth", "=", 3)
+	if names := fetchPostgreSQLFirstNames(t, monthDb); strings.Join(names, ",") != "Sharon" {
+		t.Fatalf("Expected Sharon, got %v", names)
+	}
+}
+
+func TestPostgreSQLWhereGroup(t *testing.T) {
+	db := newPostgreSQLUserNamesQuery(t)
+	db.Where("active", "=", 1, true)
+	db.WhereGroup(func(q *Query) {
+		q.Where("first_name", "=", "Steve", true)
+		q.Or()
+		q.Where("first_name", "=", "Bob", true)
+	})
+	db.Where("surname", "!=", "Jones", true)
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected only Steve, got %v", names)
+	}
+
+	orDb := newPostgreSQLUserNamesQuery(t)
+	orDb.Where("first_name", "=", "Bob", true)
+	orDb.OrWhereGroup(func(q *Query) {
+		q.Where("active", "=", 1, true)
+		q.Where("surname", "=", "Jones", true)
+	})
+	if names := fetchPostgreSQLFirstNames(t, orDb); strings.Join(names, ",") != "Bob,Juliet" {
+		t.Fatalf("Expected Bob and Juliet, got %v", names)
+	}
+}
+
+func TestPostgreSQLOrWhere(t *testing.T) {
+	db := newPostgreSQLUserNamesQuery(t)
+	db.Where("first_name", "=", "Sharon", true)
+	db.OrWhere("first_name", "=", "Bob", true)
+	db.OrWhere("first_name", "=", "Juliet", true)
+	db.Where("active", "=", 0, true)
+	//AND takes precedence so only the last OR condition is combined with active
+	if names := fetchPostgreSQLFirstNames(t, db); strings.Join(names, ",") != "Bob,Sharon" {
+		t.Fatalf("Expected Bob and Sharon, got %v", names)
+	}
+}
+
+func TestPostgreSQLUnbalancedBrackets(t *testing.T) {
+	db := newPostgreSQLUserNamesQuery(t)
+	db.OpenBracket()
+	db.Where("first_name", "=", "Steve", true)
+	if _, _, err := db.Fetch(); err == nil || !strings.Contains(err.Error(), "not closed") {
+		t.Fatalf("Expected an unclosed bracket error, got %v", err)
+	}
+
+	closeDb := newPostgreSQLUserNamesQuery(t)
+	closeDb.Where("first_name", "=", "Steve", true)
+	closeDb.CloseBracket()
+	if err := closeDb.Validate(); err == nil {
+		t.Fatal("Expected an error closing a bracket that wasn't opened")
+	}
+
+	emptyDb := newPostgreSQLUserNamesQuery(t)
+	emptyDb.Where("first_name", "=", "Steve", true)
+	emptyDb.WhereGroup(func(q *Query) {})
+	if err := emptyDb.Validate(); err == nil {
+		t.Fatal("Expected an error for an empty group")
+	}
+}
+
+func fetchPostgreSQLUpsertNames(t *testing.T) map[string]string {
+	db, _ := Open("postgres_test")
+	db.Table("upsert_test")
+	db.Cols([]string{"code", "name"})
+	results, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching upserted rows, got %s", err.Error())
+	}
+	defer close()
+	names := map[string]string{}
+	for results.Next() {
+		var code, name string
+		results.Scan(&code, &name)
+		names[code] = name
+	}
+	return names
+}
+
+func TestPostgreSQLUpsert(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	params := []interface{}{}
+	if exists, err := db.DoesTableExist("upsert_test"); err == nil && !exists {
+		db.RawNonQuery("CREATE TABLE upsert_test (code VARCHAR(20) NOT NULL PRIMARY KEY, name VARCHAR(50) NOT NULL)", params)
+	}
+	defer db.RawNonQuery("DROP TABLE upsert_test", params)
+
+	insertDb, _ := db.NewQuery()
+	insertDb.Table("upsert_test")
+	insertDb.Upsert(map[string]interface{}{
+		"code": "A",
+		"name": "First",
+	}, []string{"code"}, nil)
+	if _, err := insertDb.Save(); err != nil {
+		t.Fatalf("Failed upserting new row, got %s", err.Error())
+	}
+
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("upsert_test")
+	updateDb.Upsert(map[string]interface{}{
+		"code": "A",
+		"name": "Updated",
+	}, []string{"code"}, []string{"name"})
+	if _, err := updateDb.Save(); err != nil {
+		t.Fatalf("Failed upserting existing row, got %s", err.Error())
+	}
+	if names := fetchPostgreSQLUpsertNames(t); len(names) != 1 || names["A"] != "Updated" {
+		t.Fatalf("Expected A to be updated, got %v", names)
+	}
+
+	multiDb, _ := db.NewQuery()
+	multiDb.Table("upsert_test")
+	multiDb.UpsertMulti([]string{
+		"code",
+		"name",
+	}, [][]interface{}{
+		{"A", "Again"},
+		{"B", "Second"},
+	}, []string{"code"}, nil)
+	if _, err := multiDb.Save(); err != nil {
+		t.Fatalf("Failed upserting multiple rows, got %s", err.Error())
+	}
+	names := fetchPostgreSQLUpsertNames(t)
+	if len(names) != 2 || names["A"] != "Again" || names["B"] != "Second" {
+		t.Fatalf("Expected A updated and B inserted, got %v", names)
+	}
+
+	keyDb, _ := db.NewQuery()
+	keyDb.Table("upsert_test")
+	keyDb.Upsert(map[string]interface{}{
+		"code": "B",
+		"name": "Keyed",
+	}, nil, []string{"name"})
+	if _, err := keyDb.Save(); err == nil {
+		t.Fatalf("Expected an error upserting without conflict columns")
+	}
+}
+
+func TestPostgreSQLInsertFrom(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Steve", "Bob")
+	source, _ := db.NewQuery()
+	source.Table("users")
+	source.Cols([]string{"first_name"})
+	source.WhereInList("first_name", []interface{}{"Steve", "Bob"}, true)
+
+	db.Table("cities")
+	db.InsertFrom([]string{"city"}, source)
+	res, err := db.Save()
+	if err != nil {
+		t.Fatalf("Failed inserting from select, got %s", err.Error())
+	}
+	if num, _ := res.RowsAffected(); num != 2 {
+		t.Fatalf("Expected 2 affected rows, got %d", num)
+	}
+	if countPostgreSQLCities(t, "Steve") != 1 || countPostgreSQLCities(t, "Bob") != 1 {
+		t.Fatalf("Expected the selected names to be inserted as cities")
+	}
+}
+
+func insertPostgreSQLUserCities(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.InsertMulti([]string{"city"}, [][]interface{}{
+		{"Steve"},
+		{"Bob"},
+	}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting cities, got %s", err.Error())
+	}
+}
+
+func TestPostgreSQLUpdateJoin(t *testing.T) {
+	insertPostgreSQLUserCities(t)
+	defer deletePostgreSQLCities("Steve", "Bob", "Active Steve")
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	activeUsers, _ := db.NewQuery()
+	activeUsers.Table("users")
+	activeUsers.Cols([]string{"first_name"})
+	activeUsers.Where("active", "=", 1, true)
+
+	db.Table("cities")
+	db.JoinSub(activeUsers, "active_users", "active_users.first_name", "cities.city")
+	db.Update(map[string]interface{}{
+		"city": "Active Steve",
+	}, true)
+	db.WhereInList("cities.city", []interface{}{"Steve", "Bob"}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed running update with join, got %s", err.Error())
+	}
+	if countPostgreSQLCities(t, "Active Steve") != 1 || countPostgreSQLCities(t, "Bob") != 1 {
+		t.Fatalf("Expected only the city joined to an active user to be updated")
+	}
+}
+
+func TestPostgreSQLDeleteJoin(t *testing.T) {
+	insertPostgreSQLUserCities(t)
+	defer deletePostgreSQLCities("Steve", "Bob")
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.JoinTable("users", "users.first_name", "cities.city")
+	db.Where("users.active", "=", 0, true)
+	if _, err := db.Delete(); err != nil {
+		t.Fatalf("Failed running delete with join, got %s", err.Error())
+	}
+	if countPostgreSQLCities(t, "Steve") != 1 || countPostgreSQLCities(t, "Bob") != 0 {
+		t.Fatalf("Expected only the city joined to an inactive user to be deleted")
+	}
+}
+
+func TestPostgreSQLSaveReturning(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Returning One", "Returning Two", "Returning Three")
+	db.Table("cities")
+	db.InsertMulti([]string{"city"}, [][]interface{}{
+		{"Returning One"},
+		{"Returning Two"},
+	}, true)
+	db.Returning("id", "city")
+	results, close, err := db.SaveReturning()
+	if err != nil {
+		t.Fatalf("Failed inserting with returning columns, got %s", err.Error())
+	}
+	ids := map[string]int{}
+	for results.Next() {
+		var id int
+		var city string
+		results.Scan(&id, &city)
+		ids[city] = id
+	}
+	close()
+	if len(ids) != 2 || ids["Returning One"] == 0 || ids["Returning Two"] == 0 || ids["Returning One"] == ids["Returning Two"] {
+		t.Fatalf("Expected an id for each inserted city, got %v", ids)
+	}
+
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("cities")
+	updateDb.Update(map[string]interface{}{
+		"city": "Returning Three",
+	}, true)
+	updateDb.Where("id", "=", ids["Returning Two"], true)
+	updateDb.Returning("city")
+	results, close, err = updateDb.SaveReturning()
+	if err != nil {
+		t.Fatalf("Failed updating with returning columns, got %s", err.Error())
+	}
+	updated := []string{}
+	for results.Next() {
+		var city string
+		results.Scan(&city)
+		updated = append(updated, city)
+	}
+	close()
+	if strings.Join(updated, ",") != "Returning Three" {
+		t.Fatalf("Expected the updated city to be returned, got %v", updated)
+	}
+
+	deleteDb, _ := db.NewQuery()
+	deleteDb.Table("cities")
+	deleteDb.Where("id", "=", ids["Returning One"], true)
+	deleteDb.Returning("city")
+	results, close, err = deleteDb.DeleteReturning()
+	if err != nil {
+		t.Fatalf("Failed deleting with returning columns, got %s", err.Error())
+	}
+	deleted := []string{}
+	for results.Next() {
+		var city string
+		results.Scan(&city)
+		deleted = append(deleted, city)
+	}
+	close()
+	if strings.Join(deleted, ",") != "Returning One" || countPostgreSQLCities(t, "Returning One") != 0 {
+		t.Fatalf("Expected the deleted city to be returned, got %v", deleted)
+	}
+}
+
+func TestPostgreSQLLockOutsideTransaction(t *testing.T) {
+	db := newPostgreSQLUserNamesQuery(t)
+	db.LockForUpdate()
+	if _, _, err := db.Fetch(); err == nil {
+		t.Fatalf("Expected an error locking rows outside of a transaction")
+	}
+}
+
+func TestPostgreSQLLockForUpdate(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	names := []string{}
+	err = db.Transaction(func(tx Tx) error {
+		lockDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		lockDb.Table("users")
+		lockDb.Cols([]string{
+			"first_name",
+		})
+		lockDb.Where("active", "=", 1, true)
+		lockDb.OrderBy("first_name", "ASC")
+		lockDb.LockForUpdate()
+		lockDb.SkipLocked()
+		results, close, err := lockDb.Fetch()
+		if err != nil {
+			return err
+		}
+		defer close()
+		for results.Next() {
+			var name string
+			results.Scan(&name)
+			names = append(names, name)
+		}
+		return results.Err()
+	})
+	if err != nil {
+		t.Fatalf("Failed fetching locked rows, got %s", err.Error())
+	}
+	if strings.Join(names, ",") != "Juliet,Steve" {
+		t.Fatalf("Expected the active users, got %v", names)
+	}
+}
+
+func TestPostgreSQLAcquireLock(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	ctx := context.Background()
+	lock, err := db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring lock, got %s", err.Error())
+	}
+	if _, err := db.AcquireLock(ctx, "bezsql_test_lock", 200*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Expected ErrLockTimeout while the lock is held, got %v", err)
+	}
+	if err := lock.ReleaseLock(ctx); err != nil {
+		t.Fatalf("Failed releasing lock, got %s", err.Error())
+	}
+	lock, err = db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring released lock, got %s", err.Error())
+	}
+	if err := lock.ReleaseLock(ctx); err != nil {
+		t.Fatalf("Failed releasing lock, got %s", err.Error())
+	}
+}
+
+func TestPostgreSQLOrderedValueCount(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.InsertOrdered([]string{"id", "city"}, []interface{}{"Missing Id"}, true)
+	if _, err := db.Save(); err == nil {
+		t.Fatalf("Expected an error inserting fewer values than columns")
+	}
+
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("cities")
+	updateDb.UpdateOrdered([]string{"city"}, []interface{}{"Extra", "Value"}, true)
+	updateDb.Where("city", "=", "Extra", true)
+	if err := updateDb.Validate(); err == nil {
+		t.Fatalf("Expected an error updating with more values than columns")
+	}
+}
+
+func TestPostgreSQLReleaseLockCancelled(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	ctx := context.Background()
+	lock, err := db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring lock, got %s", err.Error())
+	}
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := lock.ReleaseLock(cancelledCtx); err == nil {
+		t.Fatalf("Expected an error releasing a lock with a cancelled context")
+	}
+	//the connection holding the lock is closed rather than returned to the pool, ending its session
+	lock, err = db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring the lock of a closed connection, got %s", err.Error())
+	}
+	if err := lock.ReleaseLock(ctx); err != nil {
+		t.Fatalf("Failed releasing lock, got %s", err.Error())
+	}
+}
+
+func TestPostgreSQLInsertStructsReturning(t *testing.T) {
+	db, err := Open("postgres_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deletePostgreSQLCities("Returning One", "Returning Two")
+	cities := []structPostgreSQLCity{
+		{City: "Returning One"},
+		{City: "Returning Two"},
+	}
+	db.Table("cities")
+	if err := db.InsertStructs(cities); err != nil {
+		t.Fatalf("Failed setting insert from structs, got %s", err.Error())
+	}
+	db.Returning("id")
+	res, err := db.Save()
+	if err != nil {
+		t.Fatalf("Failed inserting structs, got %s", err.Error())
+	}
+	if cities[0].ID == 0 || cities[1].ID != cities[0].ID+1 {
+		t.Fatalf("Expected sequential ids to be set, got %d and %d", cities[0].ID, cities[1].ID)
+	}
+	if id, err := res.LastInsertId(); err != nil || id != int64(cities[0].ID) {
+		t.Fatalf("Expected the last insert id to be the first city's id, got %d %v", id, err)
+	}
+}
+
+func TestPostgreSQLReservedWords(t *testing.T) {
+	db := newBuilder(&postgreSQL{})
+	if col := db.checkReserved("accounts.user"); col != `accounts."user"` {
+		t.Fatalf("Expected user to be quoted, got %s", col)
+	}
+	if col := db.checkReserved("order"); col != `"order"` {
+		t.Fatalf("Expected the shared reserved words to be quoted, got %s", col)
+	}
+}
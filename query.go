@@ -1,12 +1,16 @@
 package bezsql
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type where struct {
-	Type       string
+	Type string
+	//joins this condition with AND or OR instead of the current logic
+	Logic      string
 	Field      string
 	Comparator string
 	Value      interface{}
@@ -16,13 +20,18 @@ type where struct {
 	ParamNames []string
 }
 type Query struct {
+	dialect        Dialect
 	useNamedParams bool
 	paramPrefix    string
 	paramNum       int
 	wheres         []where
 }
 
+// has no effect on databases which don't use named parameters
 func (q *Query) SetParamPrefix(prefix string) {
+	if q.dialect != nil && q.dialect.ParamStyle() != NamedParams {
+		return
+	}
 	q.paramPrefix = prefix
 	q.useNamedParams = true
 }
@@ -137,6 +146,187 @@ func (q *Query) WhereNotInSub(field string, subQuery DB) {
 	q.addWhereInSub("NOT IN", field, subQuery)
 }
 
+// returns the placeholder for the next parameter and its name if parameters are named
+func (q *Query) nextParam() (string, string) {
+	if !q.useNamedParams {
+		return "?", ""
+	}
+	q.paramNum++
+	paramName := fmt.Sprintf("%s%d", q.paramPrefix, q.paramNum)
+	return fmt.Sprintf("@%s", paramName), paramName
+}
+
+// adds a condition with a value containing several parameters, e.g. ? AND ?
+func (q *Query) addParamsWhere(field string, comparator string, valueFormat string, values ...interface{}) {
+	placeholders := []interface{}{}
+	paramNames := []string{}
+	for range values {
+		placeholder, paramName := q.nextParam()
+		placeholders = append(placeholders, placeholder)
+		if paramName != "" {
+			paramNames = append(paramNames, paramName)
+		}
+	}
+	q.wheres = append(q.wheres, where{
+		Type:       "where",
+		Field:      field,
+		Comparator: comparator,
+		Value:      fmt.Sprintf(valueFormat, placeholders...),
+		Escape:     false,
+		Params:     values,
+		ParamNames: paramNames,
+	})
+}
+
+func (q *Query) WhereBetween(field string, from interface{}, to interface{}) {
+	q.addParamsWhere(field, "BETWEEN", "%s AND %s", from, to)
+}
+
+func (q *Query) WhereNotBetween(field string, from interface{}, to interface{}) {
+	q.addParamsWhere(field, "NOT BETWEEN", "%s AND %s", from, to)
+}
+
+// the character used to escape wildcards in LIKE patterns
+const likeEscapeChar = "!"
+
+// escapes the LIKE wildcards in a value so it is matched literally, e.g. to search for user input
+// with WhereLike(field, "%"+EscapeLike(input)+"%")
+func EscapeLike(value string) string {
+	replacer := strings.NewReplacer(
+		likeEscapeChar, likeEscapeChar+likeEscapeChar,
+		"%", likeEscapeChar+"%",
+		"_", likeEscapeChar+"_",
+		"[", likeEscapeChar+"[",
+	)
+	return replacer.Replace(value)
+}
+
+// matches a LIKE pattern, wildcards escaped with EscapeLike are matched literally
+func (q *Query) WhereLike(field string, pattern string) {
+	q.addParamsWhere(field, "LIKE", fmt.Sprintf("%%s ESCAPE '%s'", likeEscapeChar), pattern)
+}
+
+// matches a LIKE pattern ignoring case whatever the database's collation
+func (q *Query) WhereILike(field string, pattern string) {
+	placeholder, paramName := q.nextParam()
+	paramNames := []string{}
+	if paramName != "" {
+		paramNames = append(paramNames, paramName)
+	}
+	q.wheres = append(q.wheres, where{
+		Type:       "where",
+		Field:      iLike(q.dialect, field, placeholder),
+		Value:      fmt.Sprintf("ESCAPE '%s'", likeEscapeChar),
+		Escape:     false,
+		Params:     []interface{}{pattern},
+		ParamNames: paramNames,
+	})
+}
+
+// named parameters of the sub query are renamed so they don't clash with the parameters of this query
+func (q *Query) addWhereExists(existsType string, subQuery DB) {
+	subSql := subQuery.GenerateSelect()
+	subParamNames := subQuery.getParamNames()
+	if q.useNamedParams {
+		subSql, subParamNames = renameParams(subSql, subParamNames, fmt.Sprintf("%sExists%d_", q.paramPrefix, len(q.wheres)+1))
+	}
+	q.wheres = append(q.wheres, where{
+		Type:       "where",
+		Comparator: existsType,
+		Value:      fmt.Sprintf(" (%s) ", subSql),
+		Escape:     false,
+		Params:     subQuery.getParams(),
+		ParamNames: subParamNames,
+	})
+}
+
+func (q *Query) WhereExists(subQuery DB) {
+	q.addWhereExists("EXISTS", subQuery)
+}
+
+func (q *Query) WhereNotExists(subQuery DB) {
+	q.addWhereExists("NOT EXISTS", subQuery)
+}
+
+// compares two columns
+func (q *Query) WhereColumn(field string, comparator string, otherField string) {
+	q.Where(field, comparator, otherField, false)
+}
+
+// compares the date part of a date time column, time values are compared by their date
+func (q *Query) WhereDate(field string, comparator string, value interface{}) {
+	if t, ok := value.(time.Time); ok {
+		value = t.Format("2006-01-02")
+	}
+	q.Where(datePart(q.dialect, DatePartDate, field), comparator, value, true)
+}
+
+func (q *Query) WhereYear(field string, comparator string, year int) {
+	q.Where(datePart(q.dialect, DatePartYear, field), comparator, year, true)
+}
+
+func (q *Query) WhereMonth(field string, comparator string, month int) {
+	q.Where(datePart(q.dialect, DatePartMonth, field), comparator, month, true)
+}
+
+// adds a single condition joined with OR, conditions after it are joined with the current logic
+func (q *Query) OrWhere(field string, comparator string, value interface{}, escape bool) {
+	q.Where(field, comparator, value, escape)
+	q.wheres[len(q.wheres)-1].Logic = "OR"
+}
+
+func (q *Query) addWhereGroup(logic string, groupFunc queryFunc) {
+	q.wheres = append(q.wheres, where{
+		Type:       "group",
+		Comparator: "(",
+		Logic:      logic})
+	groupFunc(q)
+	q.wheres = append(q.wheres, where{
+		Type:       "group",
+		Comparator: ")"})
+}
+
+// wraps the conditions added by groupFunc in brackets, inside the group conditions are joined with AND
+// until Or is called and the logic outside the group is unaffected
+func (q *Query) WhereGroup(groupFunc queryFunc) {
+	q.addWhereGroup("", groupFunc)
+}
+
+// like WhereGroup but the group is joined to the previous condition with OR
+func (q *Query) OrWhereGroup(groupFunc queryFunc) {
+	q.addWhereGroup("OR", groupFunc)
+}
+
+// checks every opened bracket is closed and no bracket is empty, as either would generate invalid SQL
+func (q *Query) checkBrackets() error {
+	depth := 0
+	for i, w := range q.wheres {
+		if w.Type != "bracket" && w.Type != "group" {
+			continue
+		}
+		if w.Comparator == "(" {
+			depth++
+			continue
+		}
+		depth--
+		if depth < 0 {
+			return errors.New("closing bracket without a matching opening bracket")
+		}
+		for j := i - 1; j >= 0; j-- {
+			if q.wheres[j].Type == "where" || q.wheres[j].Comparator == ")" {
+				break
+			}
+			if q.wheres[j].Comparator == "(" {
+				return errors.New("bracket contains no conditions")
+			}
+		}
+	}
+	if depth > 0 {
+		return fmt.Errorf("%d bracket(s) not closed", depth)
+	}
+	return nil
+}
+
 func (q *Query) Or() {
 	q.wheres = append(q.wheres, where{
 		Type:       "logic",
@@ -168,15 +358,22 @@ func (q *Query) ApplyWheres() (string, []interface{}, []string) {
 	if len(q.wheres) == 0 {
 		return whereString, params, paramNames
 	}
-	first := true
+	//conditions are joined by logic except at the start and straight after an opening bracket
+	needsLogic := false
 	logic := "AND"
-	for i, w := range q.wheres {
+	//groups start with AND and restore the logic from outside once closed
+	groupLogic := []string{}
+	for _, w := range q.wheres {
+		joinLogic := logic
+		if w.Logic != "" {
+			joinLogic = w.Logic
+		}
 		switch w.Type {
 		case "where":
-			if !first && q.wheres[i-1].Type != "bracket" {
-				whereString += fmt.Sprintf(" %s ", logic)
+			if needsLogic {
+				whereString += fmt.Sprintf(" %s ", joinLogic)
 			}
-			first = false
+			needsLogic = true
 			whereString += fmt.Sprintf(" %s %s ", w.Field, w.Comparator)
 			if w.Escape {
 				if q.useNamedParams {
@@ -198,11 +395,21 @@ func (q *Query) ApplyWheres() (string, []interface{}, []string) {
 			}
 		case "logic":
 			logic = w.Comparator
-		case "bracket":
-			if w.Comparator == "(" && !first {
-				whereString += fmt.Sprintf(" %s ", logic)
+		case "bracket", "group":
+			if w.Comparator == "(" && needsLogic {
+				whereString += fmt.Sprintf(" %s ", joinLogic)
 			}
+			needsLogic = w.Comparator == ")"
 			whereString += fmt.Sprintf(" %s ", w.Comparator)
+			if w.Type == "group" {
+				if w.Comparator == "(" {
+					groupLogic = append(groupLogic, logic)
+					logic = "AND"
+				} else if len(groupLogic) > 0 {
+					logic = groupLogic[len(groupLogic)-1]
+					groupLogic = groupLogic[:len(groupLogic)-1]
+				}
+			}
 		}
 	}
 	return whereString, params, paramNames
@@ -0,0 +1,289 @@
+// Package queue is a job queue stored in a database table, built on the bezsql query builder.
+//
+// Reserved jobs are hidden from other workers until their visibility timeout passes, so a job that is
+// never acknowledged becomes available again. Jobs which fail too many times are moved to a dead letter queue.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bezberr.com/bezsql"
+)
+
+// added to the name of a queue to get the queue its failed jobs are moved to
+const DeadLetterSuffix = ".dead"
+
+// returned by Reserve when the queue has no available jobs
+var ErrEmpty = errors.New("queue is empty")
+
+// returned by Ack and Nack when the job's visibility timeout passed and it was reserved again or removed
+var ErrReservationLost = errors.New("job reservation was lost")
+
+// how long Reserve first waits before trying again when another worker reserved the job it found, doubling
+// on each attempt up to maxReserveRetryInterval
+const reserveRetryInterval = 10 * time.Millisecond
+
+const maxReserveRetryInterval = time.Second
+
+type Options struct {
+	//table the jobs are stored in
+	Table string
+	//number of times a job can be reserved before a Nack moves it to the dead letter queue
+	MaxAttempts int
+	//delay before a job that was Nacked can be reserved again, zero makes it available straight away
+	RetryDelay time.Duration
+	//renders the query used by CreateTable to create the jobs table, needed for database types without a built in
+	//query, the table needs the id, queue, payload, attempts and available_at columns
+	CreateTableQuery func(table string) string
+}
+
+var DefaultOptions = Options{
+	Table:       "queue_jobs",
+	MaxAttempts: 5,
+	RetryDelay:  10 * time.Second,
+}
+
+type Queue struct {
+	db      bezsql.DB
+	options Options
+}
+
+// a job reserved from a queue, it should be passed to Ack once processed or Nack if processing failed
+type Job struct {
+	ID      int64
+	Queue   string
+	Payload []byte
+	//number of times the job has been reserved, including this reservation
+	Attempts int
+	//time the reservation ends, used to check the job hasn't been reserved again before it's acknowledged
+	reservedUntil int64
+}
+
+// creates a queue storing its jobs using the connection of db, an empty Table or MaxAttempts is taken from DefaultOptions
+func New(db bezsql.DB, options Options) *Queue {
+	if options.Table == "" {
+		options.Table = DefaultOptions.Table
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultOptions.MaxAttempts
+	}
+	return &Queue{
+		db:      db,
+		options: options,
+	}
+}
+
+// creates the jobs table if it doesn't exist yet
+func (q *Queue) CreateTable(ctx context.Context) error {
+	exists, err := q.db.DoesTableExist(q.options.Table)
+	if err != nil || exists {
+		return err
+	}
+	var query string
+	if q.options.CreateTableQuery != nil {
+		query = q.options.CreateTableQuery(q.options.Table)
+	} else {
+		query, err = createTableQuery(q.db.GetConfig().Type, q.options.Table)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = q.db.RawNonQueryContext(ctx, query, []interface{}{})
+	return err
+}
+
+func createTableQuery(databaseType string, table string) (string, error) {
+	switch databaseType {
+	case "MySQL":
+		return fmt.Sprintf("CREATE TABLE %s (id BIGINT AUTO_INCREMENT PRIMARY KEY, queue VARCHAR(255) NOT NULL, payload LONGBLOB NOT NULL, attempts INT NOT NULL, available_at BIGINT NOT NULL, INDEX %s_available (queue, available_at))", table, table), nil
+	case "SQLServer":
+		return fmt.Sprintf("CREATE TABLE %s (id BIGINT IDENTITY(1,1) PRIMARY KEY, queue NVARCHAR(255) NOT NULL, payload VARBINARY(MAX) NOT NULL, attempts INT NOT NULL, available_at BIGINT NOT NULL); CREATE INDEX %s_available ON %s (queue, available_at);", table, table, table), nil
+	case "PostgreSQL":
+		return fmt.Sprintf("CREATE TABLE %s (id BIGSERIAL PRIMARY KEY, queue VARCHAR(255) NOT NULL, payload BYTEA NOT NULL, attempts INT NOT NULL, available_at BIGINT NOT NULL); CREATE INDEX %s_available ON %s (queue, available_at);", table, table, table), nil
+	case "SQLite":
+		return fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, queue VARCHAR(255) NOT NULL, payload BLOB NOT NULL, attempts INT NOT NULL, available_at BIGINT NOT NULL); CREATE INDEX %s_available ON %s (queue, available_at);", table, table, table), nil
+	}
+	return "", fmt.Errorf("queue table can't be created for database type %s without Options.CreateTableQuery", databaseType)
+}
+
+// adds a job to the end of a queue, returning its id
+func (q *Queue) Enqueue(ctx context.Context, queue string, payload []byte) (int64, error) {
+	insertDb, err := q.db.NewQuery()
+	if err != nil {
+		return 0, err
+	}
+	insertDb.Table(q.options.Table)
+	insertDb.Insert(map[string]interface{}{
+		"queue":        queue,
+		"payload":      payload,
+		"attempts":     0,
+		"available_at": time.Now().UnixMilli(),
+	}, true)
+	res, err := insertDb.SaveContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// reserves the oldest available job in a queue, hiding it from other workers for the visibility duration,
+// returns ErrEmpty if there are no available jobs
+func (q *Queue) Reserve(ctx context.Context, queue string, visibility time.Duration) (*Job, error) {
+	wait := reserveRetryInterval
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var job *Job
+		err := q.db.TransactionContext(ctx, func(tx bezsql.Tx) error {
+			var err error
+			job, err = q.reserveNext(ctx, tx, queue, visibility)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		//nil without an error means another worker reserved the job first
+		if job != nil {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+		if wait > maxReserveRetryInterval {
+			wait = maxReserveRetryInterval
+		}
+	}
+}
+
+// selects the oldest available job, skipping jobs locked by other workers
+func (q *Queue) selectNext(selectDb bezsql.DB, queue string, now int64) {
+	selectDb.Table(q.options.Table)
+	selectDb.Cols([]string{
+		"id",
+		"payload",
+		"attempts",
+		"available_at",
+	})
+	selectDb.Where("queue", "=", queue, true)
+	selectDb.Where("available_at", "<=", now, true)
+	selectDb.OrderBy("id", "ASC")
+	selectDb.LimitBy(1)
+	selectDb.LockForUpdate()
+	selectDb.SkipLocked()
+}
+
+func (q *Queue) reserveNext(ctx context.Context, tx bezsql.Tx, queue string, visibility time.Duration) (*Job, error) {
+	now := time.Now().UnixMilli()
+	selectDb, err := tx.NewQuery()
+	if err != nil {
+		return nil, err
+	}
+	q.selectNext(selectDb, queue, now)
+	results, cancel, err := selectDb.FetchContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job := Job{
+		Queue: queue,
+	}
+	var availableAt int64
+	found := results.Next()
+	if found {
+		err = results.Scan(&job.ID, &job.Payload, &job.Attempts, &availableAt)
+	} else {
+		err = results.Err()
+	}
+	results.Close()
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrEmpty
+	}
+
+	job.Attempts++
+	job.reservedUntil = now + visibility.Milliseconds()
+	//the available_at check stops the job being reserved twice on databases without row locks
+	updated, err := q.updateJob(ctx, tx, job.ID, availableAt, map[string]interface{}{
+		"attempts":     job.Attempts,
+		"available_at": job.reservedUntil,
+	})
+	if err != nil || !updated {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// a database or transaction to create queries on
+type queryCreator interface {
+	NewQuery() (bezsql.DB, error)
+}
+
+// updates a job as long as its available_at hasn't changed, reporting whether it was updated
+func (q *Queue) updateJob(ctx context.Context, db queryCreator, id int64, availableAt int64, values map[string]interface{}) (bool, error) {
+	updateDb, err := db.NewQuery()
+	if err != nil {
+		return false, err
+	}
+	updateDb.Table(q.options.Table)
+	updateDb.Update(values, true)
+	updateDb.Where("id", "=", id, true)
+	updateDb.Where("available_at", "=", availableAt, true)
+	res, err := updateDb.SaveContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	num, err := res.RowsAffected()
+	return num > 0, err
+}
+
+// removes a processed job from the queue
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	deleteDb, err := q.db.NewQuery()
+	if err != nil {
+		return err
+	}
+	deleteDb.Table(q.options.Table)
+	deleteDb.Where("id", "=", job.ID, true)
+	deleteDb.Where("available_at", "=", job.reservedUntil, true)
+	res, err := deleteDb.DeleteContext(ctx)
+	if err != nil {
+		return err
+	}
+	num, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrReservationLost
+	}
+	return nil
+}
+
+// releases a job that failed so it can be reserved again after the retry delay, or moves it to the dead letter
+// queue once it has been reserved MaxAttempts times
+func (q *Queue) Nack(ctx context.Context, job *Job) error {
+	values := map[string]interface{}{
+		"available_at": time.Now().Add(q.options.RetryDelay).UnixMilli(),
+	}
+	if job.Attempts >= q.options.MaxAttempts {
+		values["available_at"] = time.Now().UnixMilli()
+		values["queue"] = job.Queue + DeadLetterSuffix
+	}
+	updated, err := q.updateJob(ctx, q.db, job.ID, job.reservedUntil, values)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrReservationLost
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bezberr.com/bezsql"
+)
+
+func init() {
+	bezsql.SetConnections(map[string]bezsql.Config{
+		"queue_test": {
+			Type:     "SQLite",
+			Database: ":memory:",
+		},
+		"queue_mysql_test": {
+			Type:     "MySQL",
+			Host:     "localhost",
+			Port:     3306,
+			Username: "root",
+			Password: "",
+			Database: "test",
+		},
+		"queue_postgres_test": {
+			Type:     "PostgreSQL",
+			Host:     "localhost",
+			Port:     5432,
+			Username: "postgres",
+			Password: "",
+			Database: "test",
+		},
+		"queue_sqlserver_test": {
+			Type:     "SQLServer",
+			Host:     "localhost",
+			Port:     1433,
+			Username: "sa",
+			Password: "SuperSecurePassword!",
+			Database: "test",
+		},
+	})
+}
+
+func newTestQueue(t *testing.T, options Options) *Queue {
+	db, err := bezsql.Open("queue_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	q := New(db, options)
+	if err := q.CreateTable(context.Background()); err != nil {
+		t.Fatalf("Failed creating queue table, got %s", err.Error())
+	}
+	return q
+}
+
+func TestCreateTableExists(t *testing.T) {
+	q := newTestQueue(t, Options{Table: "exists_jobs"})
+	if err := q.CreateTable(context.Background()); err != nil {
+		t.Fatalf("Expected no error when the table already exists, got %s", err.Error())
+	}
+}
+
+func TestEnqueueReserveAck(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, Options{Table: "ack_jobs"})
+	firstId, err := q.Enqueue(ctx, "emails", []byte("first"))
+	if err != nil {
+		t.Fatalf("Failed enqueueing job, got %s", err.Error())
+	}
+	if _, err := q.Enqueue(ctx, "emails", []byte("second")); err != nil {
+		t.Fatalf("Failed enqueueing job, got %s", err.Error())
+	}
+	if _, err := q.Enqueue(ctx, "reports", []byte("report")); err != nil {
+		t.Fatalf("Failed enqueueing job, got %s", err.Error())
+	}
+
+	job, err := q.Reserve(ctx, "emails", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed reserving job, got %s", err.Error())
+	}
+	if job.ID != firstId || string(job.Payload) != "first" || job.Attempts != 1 {
+		t.Fatalf("Expected the first job on its first attempt, got %d %s %d", job.ID, job.Payload, job.Attempts)
+	}
+	second, err := q.Reserve(ctx, "emails", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed reserving job, got %s", err.Error())
+	}
+	if string(second.Payload) != "second" {
+		t.Fatalf("Expected the reserved job to be skipped, got %s", second.Payload)
+	}
+	if _, err := q.Reserve(ctx, "emails", time.Minute); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Expected ErrEmpty when every job is reserved, got %v", err)
+	}
+
+	if err := q.Ack(ctx, job); err != nil {
+		t.Fatalf("Failed acknowledging job, got %s", err.Error())
+	}
+	if err := q.Ack(ctx, job); !errors.Is(err, ErrReservationLost) {
+		t.Fatalf("Expected ErrReservationLost acknowledging a removed job, got %v", err)
+	}
+}
+
+func TestVisibilityTimeout(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, Options{Table: "visibility_jobs"})
+	if _, err := q.Enqueue(ctx, "emails", []byte("job")); err != nil {
+		t.Fatalf("Failed enqueueing job, got %s", err.Error())
+	}
+	job, err := q.Reserve(ctx, "emails", 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed reserving job, got %s", err.Error())
+	}
+	time.Sleep(30 * time.Millisecond)
+	again, err := q.Reserve(ctx, "emails", time.Minute)
+	if err != nil {
+		t.Fatalf("Expected the job to be available after its visibility timeout, got %s", err.Error())
+	}
+	if again.ID != job.ID || again.Attempts != 2 {
+		t.Fatalf("Expected the same job on its second attempt, got %d on attempt %d", again.ID, again.Attempts)
+	}
+	if err := q.Ack(ctx, job); !errors.Is(err, ErrReservationLost) {
+		t.Fatalf("Expected ErrReservationLost acknowledging an expired reservation, got %v", err)
+	}
+	if err := q.Ack(ctx, again); err != nil {
+		t.Fatalf("Failed acknowledging job, got %s", err.Error())
+	}
+}
+
+func TestNackDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, Options{
+		Table:       "nack_jobs",
+		MaxAttempts: 2,
+	})
+	id, err := q.Enqueue(ctx, "emails", []byte("failing"))
+	if err != nil {
+		t.Fatalf("Failed enqueueing job, got %s", err.Error())
+	}
+	for attempt := 1; attempt <= 2; attempt++ {
+		job, err := q.Reserve(ctx, "emails", time.Minute)
+		if err != nil {
+			t.Fatalf("Failed reserving attempt %d, got %s", attempt, err.Error())
+		}
+		if job.Attempts != attempt {
+			t.Fatalf("Expected attempt %d, got %d", attempt, job.Attempts)
+		}
+		if err := q.Nack(ctx, job); err != nil {
+			t.Fatalf("Failed releasing job, got %s", err.Error())
+		}
+	}
+	if _, err := q.Reserve(ctx, "emails", time.Minute); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Expected the failed job to leave the queue, got %v", err)
+	}
+	dead, err := q.Reserve(ctx, "emails"+DeadLetterSuffix, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed reserving dead letter job, got %s", err.Error())
+	}
+	if dead.ID != id || string(dead.Payload) != "failing" {
+		t.Fatalf("Expected the failed job in the dead letter queue, got %d %s", dead.ID, dead.Payload)
+	}
+}
+
+func TestCreateTableQuery(t *testing.T) {
+	ctx := context.Background()
+	called := false
+	q := newTestQueue(t, Options{
+		Table: "custom_jobs",
+		CreateTableQuery: func(table string) string {
+			called = true
+			return "CREATE TABLE " + table + " (id INTEGER PRIMARY KEY AUTOINCREMENT, queue TEXT NOT NULL, payload BLOB NOT NULL, attempts INT NOT NULL, available_at BIGINT NOT NULL)"
+		},
+	})
+	if !called {
+		t.Fatalf("Expected the table to be created with CreateTableQuery")
+	}
+	if _, err := q.Enqueue(ctx, "emails", []byte("job")); err != nil {
+		t.Fatalf("Failed enqueueing job, got %s", err.Error())
+	}
+	if _, err := q.Reserve(ctx, "emails", time.Minute); err != nil {
+		t.Fatalf("Failed reserving job, got %s", err.Error())
+	}
+}
+
+func TestReserveCancelled(t *testing.T) {
+	q := newTestQueue(t, Options{Table: "cancelled_jobs"})
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := q.Enqueue(ctx, "emails", []byte("job")); err != nil {
+		t.Fatalf("Failed enqueueing job, got %s", err.Error())
+	}
+	cancel()
+	if _, err := q.Reserve(ctx, "emails", time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled reserving with a cancelled context, got %v", err)
+	}
+}
+
+// the select reserving the next job, generated without connecting to the database
+func generateReserveSelect(t *testing.T, database string) string {
+	db, err := bezsql.Open(database)
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	q := New(db, Options{Table: "jobs"})
+	selectDb, _ := db.NewQuery()
+	q.selectNext(selectDb, "emails", time.Now().UnixMilli())
+	return selectDb.GenerateSelect()
+}
+
+func TestMySQLReserveSkipsLocked(t *testing.T) {
+	if query := generateReserveSelect(t, "queue_mysql_test"); !strings.Contains(query, "FOR UPDATE SKIP LOCKED") {
+		t.Fatalf("Expected the reserve select to skip locked jobs, got %s", query)
+	}
+}
+
+func TestPostgreSQLReserveSkipsLocked(t *testing.T) {
+	if query := generateReserveSelect(t, "queue_postgres_test"); !strings.Contains(query, "FOR UPDATE SKIP LOCKED") {
+		t.Fatalf("Expected the reserve select to skip locked jobs, got %s", query)
+	}
+}
+
+func TestSQLServerReserveSkipsLocked(t *testing.T) {
+	if query := generateReserveSelect(t, "queue_sqlserver_test"); !strings.Contains(query, "READPAST") || !strings.Contains(query, "UPDLOCK") {
+		t.Fatalf("Expected the reserve select to skip locked jobs, got %s", query)
+	}
+}
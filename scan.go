@@ -0,0 +1,161 @@
+package bezsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// runs the select and scans every row into dest, which should be a pointer to a slice of structs or struct pointers
+func (db *builder) FetchInto(dest interface{}) error {
+	return db.FetchIntoContext(context.Background(), dest)
+}
+
+func (db *builder) FetchIntoContext(ctx context.Context, dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("FetchInto expects a pointer to a slice, got %T", dest)
+	}
+	sliceValue := destValue.Elem()
+	elemType := sliceValue.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("FetchInto expects a slice of structs, got %T", dest)
+	}
+
+	results, cancel, err := db.FetchContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer results.Close()
+
+	fields, err := mapColumns(results, structType)
+	if err != nil {
+		return err
+	}
+	rows := reflect.MakeSlice(sliceValue.Type(), 0, 0)
+	for results.Next() {
+		row := reflect.New(structType)
+		if err := results.Scan(scanTargets(row.Elem(), fields)...); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			rows = reflect.Append(rows, row)
+		} else {
+			rows = reflect.Append(rows, row.Elem())
+		}
+	}
+	if err := results.Err(); err != nil {
+		return err
+	}
+	sliceValue.Set(rows)
+	return nil
+}
+
+// runs the select limited to one row and scans it into dest, which should be a pointer to a struct,
+// returns sql.ErrNoRows if nothing was found
+func (db *builder) FirstInto(dest interface{}) error {
+	return db.FirstIntoContext(context.Background(), dest)
+}
+
+func (db *builder) FirstIntoContext(ctx context.Context, dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("FirstInto expects a pointer to a struct, got %T", dest)
+	}
+
+	limit := db.limitBy
+	db.LimitBy(1)
+	results, cancel, err := db.FetchContext(ctx)
+	db.LimitBy(limit)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer results.Close()
+
+	fields, err := mapColumns(results, destValue.Elem().Type())
+	if err != nil {
+		return err
+	}
+	if !results.Next() {
+		if err := results.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return results.Scan(scanTargets(destValue.Elem(), fields)...)
+}
+
+// finds the field index of each result column, erroring if a column has nowhere to go
+func mapColumns(results *sql.Rows, structType reflect.Type) ([][]int, error) {
+	columns, err := results.Columns()
+	if err != nil {
+		return nil, err
+	}
+	structFields := map[string][]int{}
+	for _, field := range mappedFields(structType) {
+		structFields[strings.ToLower(field.column)] = field.index
+	}
+
+	fields := [][]int{}
+	unmapped := []string{}
+	for _, column := range columns {
+		index, found := structFields[strings.ToLower(column)]
+		if !found {
+			unmapped = append(unmapped, column)
+			continue
+		}
+		fields = append(fields, index)
+	}
+	if len(unmapped) > 0 {
+		return nil, fmt.Errorf("no field in %s for column(s) %s", structType, strings.Join(unmapped, ", "))
+	}
+	return fields, nil
+}
+
+// returns pointers to the fields of row, creating any nil embedded struct pointers on the way
+func scanTargets(row reflect.Value, fields [][]int) []interface{} {
+	targets := []interface{}{}
+	for _, index := range fields {
+		targets = append(targets, fieldByIndex(row, index).Addr().Interface())
+	}
+	return targets
+}
+
+func fieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for i, fieldNum := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldNum)
+	}
+	return value
+}
+
+// converts a go field name to a column name, UserID becomes user_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var snake strings.Builder
+	for i, char := range runes {
+		if unicode.IsUpper(char) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))) {
+				snake.WriteRune('_')
+			}
+			char = unicode.ToLower(char)
+		}
+		snake.WriteRune(char)
+	}
+	return snake.String()
+}
@@ -2,11 +2,16 @@ package bezsql
 
 import (
 	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 )
 
 var openConnections map[string]*sql.DB = make(map[string]*sql.DB)
 
+var namedParamPattern = regexp.MustCompile(`@\w+`)
+
 type queryFunc func(*Query)
 
 type join struct {
@@ -15,6 +20,21 @@ type join struct {
 	Query      Query
 	Params     []interface{}
 	ParamNames []string
+	//joined by CrossApply or OuterApply
+	Apply bool
+}
+
+// a select combined with the main query by UNION, INTERSECT or EXCEPT
+type compound struct {
+	Type  string
+	Query DB
+}
+
+// a common table expression added to the start of a query, RecursiveQuery is combined with Query by UNION ALL
+type cte struct {
+	Name           string
+	Query          DB
+	RecursiveQuery DB
 }
 
 type orderBy struct {
@@ -22,6 +42,59 @@ type orderBy struct {
 	Direction string
 }
 
+// result response doesn't include a usable last insert id for every database so creating a custom implementation
+type insertResult struct {
+	rowsAffected int64
+	lastInsertId int64
+	err          error
+}
+
+func (result *insertResult) RowsAffected() (int64, error) {
+	return result.rowsAffected, result.err
+}
+func (result *insertResult) LastInsertId() (int64, error) {
+	return result.lastInsertId, result.err
+}
+
+// splits a map of column values into columns sorted by name and their matching values
+func sortedValues(values map[string]interface{}) ([]string, []interface{}) {
+	columns := []string{}
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	vals := []interface{}{}
+	for _, column := range columns {
+		vals = append(vals, values[column])
+	}
+	return columns, vals
+}
+
+// checks an ordered insert or update has a value for every column
+func checkValueCount(queryType string, columns []string, vals []interface{}) error {
+	if len(columns) != len(vals) {
+		return fmt.Errorf("%s has %d column(s) but %d value(s)", queryType, len(columns), len(vals))
+	}
+	return nil
+}
+
+// gives the named parameters of a query a new prefix so they can't clash with the query they are combined into
+func renameParams(query string, paramNames []string, prefix string) (string, []string) {
+	renamed := map[string]string{}
+	newParamNames := []string{}
+	for _, name := range paramNames {
+		renamed[name] = prefix + name
+		newParamNames = append(newParamNames, prefix+name)
+	}
+	query = namedParamPattern.ReplaceAllStringFunc(query, func(param string) string {
+		if newName, ok := renamed[param[1:]]; ok {
+			return "@" + newName
+		}
+		return param
+	})
+	return query, newParamNames
+}
+
 func joinErrors(errors []error) string {
 	errorStrings := []string{}
 	for _, err := range errors {
@@ -0,0 +1,105 @@
+package bezsql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+type sQLite struct{}
+
+func (d *sQLite) Connect(config Config) (*sql.DB, error) {
+	odb, err := sql.Open("sqlite3", config.Database)
+	if err != nil {
+		return odb, err
+	}
+	if config.Database == ":memory:" {
+		//every new connection to :memory: creates a new empty database so keep the pool to a single connection
+		odb.SetMaxOpenConns(1)
+	}
+	return odb, nil
+}
+
+func (d *sQLite) ParamStyle() ParamStyle {
+	return QuestionParams
+}
+
+func (d *sQLite) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf("\"%s\"", identifier)
+}
+
+func (d *sQLite) LimitOffset(limit int, offset int, ordered bool) string {
+	query := ""
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d ", limit)
+	} else if offset > 0 {
+		//SQLite requires a limit when using an offset, -1 removes the upper bound
+		query += " LIMIT -1 "
+	}
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d ", offset)
+	}
+	return query
+}
+
+func (d *sQLite) InsertIdStrategy() InsertIdStrategy {
+	return InsertIdLast
+}
+
+func (d *sQLite) TableExistsQuery(config Config, table string) (string, []interface{}) {
+	return "SELECT COUNT(*) num FROM sqlite_master WHERE type = 'table' AND name = ?", []interface{}{
+		table,
+	}
+}
+
+// pragma_table_info is a table valued function so the table name is passed as an argument rather than a where condition
+func (d *sQLite) ColumnExistsQuery(config Config, table string, column string) (string, []interface{}) {
+	return "SELECT COUNT(*) num FROM pragma_table_info(?) WHERE name = ?", []interface{}{
+		table,
+		column,
+	}
+}
+
+// SQLite supports full joins from version 3.39.0, it doesn't support LATERAL so apply joins aren't supported
+func (d *sQLite) SupportsFullJoin() bool {
+	return true
+}
+
+// dates are stored as text so are compared as text, with years and months converted to numbers
+func (d *sQLite) DatePart(part DatePart, field string) string {
+	switch part {
+	case DatePartYear:
+		return fmt.Sprintf("CAST(strftime('%%Y', %s) AS INTEGER)", field)
+	case DatePartMonth:
+		return fmt.Sprintf("CAST(strftime('%%m', %s) AS INTEGER)", field)
+	}
+	return fmt.Sprintf("date(%s)", field)
+}
+
+func (d *sQLite) Upsert(table string, columns []string, rows [][]string, conflictColumns []string, updateColumns []string) string {
+	return onConflictUpsert(table, columns, rows, conflictColumns, updateColumns)
+}
+
+func (d *sQLite) UpsertNeedsConflictColumns() bool {
+	return true
+}
+
+// SQLite has no row locks, a write transaction locks the whole database so nothing is added
+func (d *sQLite) LockClause(mode LockMode, wait LockWait) (string, string) {
+	return "", ""
+}
+
+// SQLite has no joins in DELETE so rows are matched by rowid, tables created WITHOUT ROWID can't be used
+func (d *sQLite) JoinedWriteStyle() (JoinedWriteStyle, string) {
+	return JoinedWriteRowId, "rowid"
+}
+
+func (d *sQLite) IsRetryable(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
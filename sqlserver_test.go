@@ -1,10 +1,16 @@
 package bezsql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
 )
 
 func init() {
@@ -25,6 +31,18 @@ func init() {
 			Password: "",
 			Database: "test",
 		},
+		"postgres_test": {
+			Type:     "PostgreSQL",
+			Host:     "localhost",
+			Port:     5432,
+			Username: "postgres",
+			Password: "",
+			Database: "test",
+		},
+		"sqlite_test": {
+			Type:     "SQLite",
+			Database: ":memory:",
+		},
 	})
 	db, err := Open("sqlserver_test")
 	if err != nil {
@@ -1376,3 +1394,1671 @@ func BenchmarkSQLServerNonConc(b *testing.B) {
 		runSQLServerNonConcurrent()
 	}
 }
+
+func TestSQLServerFetchContext(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+	})
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := db.FetchContext(cancelledCtx); err == nil {
+		t.Fatal("Expected query with a cancelled context to fail")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, close, err := db.FetchContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	rowNum := 0
+	for res.Next() {
+		rowNum++
+	}
+	if rowNum != 4 {
+		t.Fatalf("Failed fetching rows, expected 4 got %d", rowNum)
+	}
+}
+
+func TestSQLServerSaveAndDeleteContext(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db.Table("cities")
+	db.Insert(map[string]interface{}{
+		"city": "Matlock",
+	}, true)
+	res, err := db.SaveContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed inserting row, got %s", err.Error())
+	}
+	id, _ := res.LastInsertId()
+
+	deleteDb, _ := db.NewQuery()
+	deleteDb.Table("cities")
+	deleteDb.Where("id", "=", id, true)
+	res, err = deleteDb.DeleteContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed deleting row, got %s", err.Error())
+	}
+	if r, _ := res.RowsAffected(); r != 1 {
+		t.Fatalf("Should have deleted 1 record, actually deleted %d", r)
+	}
+}
+
+func countSQLServerCities(t *testing.T, city string) int {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.Cols([]string{
+		db.Count("id", "num"),
+	})
+	db.Where("city", "=", city, true)
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed running query, got %s", err.Error())
+	}
+	defer close()
+	num := 0
+	for res.Next() {
+		res.Scan(&num)
+	}
+	return num
+}
+
+func deleteSQLServerCities(cities ...string) {
+	db, _ := Open("sqlserver_test")
+	values := []interface{}{}
+	for _, city := range cities {
+		values = append(values, city)
+	}
+	db.Table("cities")
+	db.WhereInList("city", values, true)
+	db.Delete()
+}
+
+func TestSQLServerTransactionCommit(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Committed One", "Committed Two")
+	err = db.Transaction(func(tx Tx) error {
+		insertDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		insertDb.Table("cities")
+		insertDb.InsertMulti([]string{
+			"city",
+		}, [][]interface{}{
+			{"Committed One"},
+			{"Committed Two"},
+		}, true)
+		_, err = insertDb.Save()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed running transaction, got %s", err.Error())
+	}
+	if num := countSQLServerCities(t, "Committed One") + countSQLServerCities(t, "Committed Two"); num != 2 {
+		t.Fatalf("Expected 2 committed rows, got %d", num)
+	}
+}
+
+func TestSQLServerTransactionRollback(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Rolled Back")
+	rollbackErr := errors.New("roll back")
+	err = db.Transaction(func(tx Tx) error {
+		insertDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		insertDb.Table("cities")
+		insertDb.Insert(map[string]interface{}{
+			"city": "Rolled Back",
+		}, true)
+		if _, err := insertDb.Save(); err != nil {
+			return err
+		}
+		return rollbackErr
+	})
+	if !errors.Is(err, rollbackErr) {
+		t.Fatalf("Expected the transaction error to be returned, got %v", err)
+	}
+	if num := countSQLServerCities(t, "Rolled Back"); num != 0 {
+		t.Fatalf("Expected inserted row to be rolled back, found %d", num)
+	}
+}
+
+func TestSQLServerTransactionPanic(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Panicked")
+	func() {
+		defer func() {
+			if p := recover(); p == nil {
+				t.Fatal("Expected the panic to be passed on")
+			}
+		}()
+		db.Transaction(func(tx Tx) error {
+			insertDb, _ := tx.NewQuery()
+			insertDb.Table("cities")
+			insertDb.Insert(map[string]interface{}{
+				"city": "Panicked",
+			}, true)
+			insertDb.Save()
+			panic("transaction panic")
+		})
+	}()
+	if num := countSQLServerCities(t, "Panicked"); num != 0 {
+		t.Fatalf("Expected inserted row to be rolled back, found %d", num)
+	}
+}
+
+func TestSQLServerTransactionSavepoint(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Kept", "Discarded")
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Failed starting transaction, got %s", err.Error())
+	}
+	keptDb, _ := tx.NewQuery()
+	keptDb.Table("cities")
+	keptDb.Insert(map[string]interface{}{
+		"city": "Kept",
+	}, true)
+	if _, err := keptDb.Save(); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed inserting row, got %s", err.Error())
+	}
+	if err := tx.Savepoint("before_discard"); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed creating savepoint, got %s", err.Error())
+	}
+	discardedDb, _ := tx.NewQuery()
+	discardedDb.Table("cities")
+	discardedDb.Insert(map[string]interface{}{
+		"city": "Discarded",
+	}, true)
+	if _, err := discardedDb.Save(); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed inserting row, got %s", err.Error())
+	}
+	if err := tx.RollbackTo("before_discard"); err != nil {
+		tx.Rollback()
+		t.Fatalf("Failed rolling back to savepoint, got %s", err.Error())
+	}
+	if err := tx.Savepoint("bad name; DROP TABLE cities"); err == nil {
+		tx.Rollback()
+		t.Fatal("Expected invalid savepoint name to be rejected")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed committing transaction, got %s", err.Error())
+	}
+	if num := countSQLServerCities(t, "Kept"); num != 1 {
+		t.Fatalf("Expected 1 kept row, got %d", num)
+	}
+	if num := countSQLServerCities(t, "Discarded"); num != 0 {
+		t.Fatalf("Expected discarded row to be rolled back, got %d", num)
+	}
+}
+
+func TestSQLServerTransactionRetry(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Retried")
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+	calls := 0
+	attempts, err := db.TransactionRetry(context.Background(), policy, func(tx Tx) error {
+		calls++
+		insertDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		insertDb.Table("cities")
+		insertDb.Insert(map[string]interface{}{
+			"city": "Retried",
+		}, true)
+		if _, err := insertDb.Save(); err != nil {
+			return err
+		}
+		if calls == 1 {
+			return mssql.Error{Number: 1205, Message: "Transaction was deadlocked"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed running transaction, got %s", err.Error())
+	}
+	if attempts != 2 || calls != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", attempts)
+	}
+	if num := countSQLServerCities(t, "Retried"); num != 1 {
+		t.Fatalf("Expected only the retried insert to be committed, found %d", num)
+	}
+}
+
+func TestSQLServerTransactionRetryGivesUp(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+	attempts, err := db.TransactionRetry(context.Background(), policy, func(tx Tx) error {
+		return mssql.Error{Number: 1205, Message: "Transaction was deadlocked"}
+	})
+	if err == nil {
+		t.Fatal("Expected the deadlock error to be returned")
+	}
+	if attempts != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", attempts)
+	}
+	otherErr := errors.New("not a deadlock")
+	attempts, err = db.TransactionRetry(context.Background(), policy, func(tx Tx) error {
+		return otherErr
+	})
+	if !errors.Is(err, otherErr) {
+		t.Fatalf("Expected the transaction error to be returned, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("Expected errors that aren't deadlocks not to be retried, got %d attempts", attempts)
+	}
+}
+
+type scanSQLServerName struct {
+	FirstName string
+	Surname   string `db:"surname"`
+}
+
+type scanSQLServerUser struct {
+	scanSQLServerName
+	ID        int64          `db:"id"`
+	Email     sql.NullString `db:"email"`
+	CountryID *int64
+	Ignored   string `db:"-"`
+}
+
+func TestSQLServerFetchInto(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+		"email",
+		"country_id",
+	})
+	db.WhereInList("first_name", []interface{}{"Steve", "Bob"}, true)
+	db.OrderBy("first_name", "ASC")
+	users := []scanSQLServerUser{}
+	if err := db.FetchInto(&users); err != nil {
+		t.Fatalf("Failed fetching into struct, got %s", err.Error())
+	}
+	if len(users) != 2 {
+		t.Fatalf("Expected 2 users, got %d", len(users))
+	}
+	bob, steve := users[0], users[1]
+	if bob.FirstName != "Bob" || bob.Surname != "Briar" || bob.ID == 0 {
+		t.Fatalf("Expected Bob Briar, got %+v", bob)
+	}
+	if bob.Email.Valid || bob.CountryID != nil {
+		t.Fatalf("Expected null email and country for Bob, got %+v", bob)
+	}
+	if steve.Email.String != "ste@ber.com" || steve.CountryID == nil || *steve.CountryID != 1 {
+		t.Fatalf("Expected email and country for Steve, got %+v", steve)
+	}
+
+	pointers := []*scanSQLServerUser{}
+	if err := db.FetchInto(&pointers); err != nil {
+		t.Fatalf("Failed fetching into struct pointers, got %s", err.Error())
+	}
+	if len(pointers) != 2 || pointers[1].FirstName != "Steve" {
+		t.Fatalf("Expected 2 users ending with Steve, got %d", len(pointers))
+	}
+}
+
+func TestSQLServerFirstInto(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+	})
+	db.Where("first_name", "=", "Sharon", true)
+	user := scanSQLServerUser{}
+	if err := db.FirstInto(&user); err != nil {
+		t.Fatalf("Failed fetching first into struct, got %s", err.Error())
+	}
+	if user.FirstName != "Sharon" || user.Surname != "Pollard" {
+		t.Fatalf("Expected Sharon Pollard, got %+v", user)
+	}
+
+	missingDb, _ := db.NewQuery()
+	missingDb.Table("users")
+	missingDb.Cols([]string{
+		"id",
+	})
+	missingDb.Where("first_name", "=", "Nobody", true)
+	if err := missingDb.FirstInto(&user); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestSQLServerFetchIntoUnmappedColumn(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"postcode",
+	})
+	users := []scanSQLServerUser{}
+	err = db.FetchInto(&users)
+	if err == nil || !strings.Contains(err.Error(), "postcode") {
+		t.Fatalf("Expected an error naming the postcode column, got %v", err)
+	}
+}
+
+func TestSQLServerTypedSelect(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+	})
+	db.Where("surname", "=", "Jones", true)
+	users, err := Select[scanSQLServerUser](db)
+	if err != nil {
+		t.Fatalf("Failed selecting users, got %s", err.Error())
+	}
+	if len(users) != 1 || users[0].FirstName != "Juliet" {
+		t.Fatalf("Expected Juliet Jones, got %+v", users)
+	}
+
+	_, err = First[*scanSQLServerUser](db)
+	if err == nil {
+		t.Fatal("Expected an error using First with a pointer type")
+	}
+	first, err := First[scanSQLServerUser](db)
+	if err != nil {
+		t.Fatalf("Failed selecting first user, got %s", err.Error())
+	}
+	if first.FirstName != "Juliet" {
+		t.Fatalf("Expected Juliet, got %+v", first)
+	}
+}
+
+func TestSQLServerTypedRows(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"surname",
+	})
+	db.OrderBy("id", "ASC")
+	rows, err := FetchRows[*scanSQLServerUser](db)
+	if err != nil {
+		t.Fatalf("Failed fetching rows, got %s", err.Error())
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		names = append(names, rows.Value().FirstName)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Failed iterating rows, got %s", err.Error())
+	}
+	if strings.Join(names, ",") != "Steve,Bob,Sharon,Juliet" {
+		t.Fatalf("Expected all 4 users in order, got %v", names)
+	}
+}
+
+func TestSQLServerFetchMaps(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"first_name",
+		"email",
+		"country_id",
+		"date_of_birth",
+	})
+	db.WhereInList("first_name", []interface{}{"Steve", "Bob"}, true)
+	db.OrderBy("first_name", "ASC")
+	rows, err := db.FetchMaps()
+	if err != nil {
+		t.Fatalf("Failed fetching maps, got %s", err.Error())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	bob, steve := rows[0], rows[1]
+	if bob["first_name"] != "Bob" || bob["email"] != nil || bob["country_id"] != nil {
+		t.Fatalf("Expected Bob with null email and country, got %v", bob)
+	}
+	if steve["email"] != "ste@ber.com" {
+		t.Fatalf("Expected email to be a string, got %#v", steve["email"])
+	}
+	if countryId, ok := steve["country_id"].(int64); !ok || countryId != 1 {
+		t.Fatalf("Expected country_id to be an int64, got %#v", steve["country_id"])
+	}
+	dateOfBirth, ok := steve["date_of_birth"].(time.Time)
+	if !ok || dateOfBirth.Year() != 1993 || dateOfBirth.Month() != time.July {
+		t.Fatalf("Expected date_of_birth to be a time, got %#v", steve["date_of_birth"])
+	}
+}
+
+func TestSQLServerColumns(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"id",
+		"first_name",
+		"email",
+	})
+	columns, err := db.Columns()
+	if err != nil {
+		t.Fatalf("Failed getting columns, got %s", err.Error())
+	}
+	if len(columns) != 3 {
+		t.Fatalf("Expected 3 columns, got %d", len(columns))
+	}
+	for i, name := range []string{"id", "first_name", "email"} {
+		if columns[i].Name != name || columns[i].DatabaseType == "" {
+			t.Fatalf("Expected column %s with a database type, got %+v", name, columns[i])
+		}
+	}
+}
+
+type structSQLServerCity struct {
+	ID   int64  `db:"id,pk"`
+	City string `db:"city"`
+}
+
+func TestSQLServerInsertAndUpdateStruct(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Struct City", "Updated Struct City")
+	city := structSQLServerCity{
+		City: "Struct City",
+	}
+	db.Table("cities")
+	if err := db.InsertStruct(&city); err != nil {
+		t.Fatalf("Failed setting insert from struct, got %s", err.Error())
+	}
+	res, err := db.Save()
+	if err != nil {
+		t.Fatalf("Failed inserting struct, got %s", err.Error())
+	}
+	lastId, _ := res.LastInsertId()
+	if city.ID == 0 || city.ID != lastId {
+		t.Fatalf("Expected the struct id to be set to %d, got %d", lastId, city.ID)
+	}
+
+	city.City = "Updated Struct City"
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("cities")
+	if err := updateDb.UpdateStruct(&city, "city"); err != nil {
+		t.Fatalf("Failed setting update from struct, got %s", err.Error())
+	}
+	if _, err := updateDb.Save(); err != nil {
+		t.Fatalf("Failed updating struct, got %s", err.Error())
+	}
+	if num := countSQLServerCities(t, "Updated Struct City"); num != 1 {
+		t.Fatalf("Expected the city to be updated, found %d", num)
+	}
+
+	invalidDb, _ := db.NewQuery()
+	invalidDb.Table("cities")
+	if err := invalidDb.UpdateStruct(&city, "population"); err == nil {
+		t.Fatal("Expected an error updating a column the struct doesn't have")
+	}
+}
+
+func TestSQLServerInsertStructs(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Struct One", "Struct Two")
+	cities := []structSQLServerCity{
+		{City: "Struct One"},
+		{City: "Struct Two"},
+	}
+	db.Table("cities")
+	if err := db.InsertStructs(cities); err != nil {
+		t.Fatalf("Failed setting insert from structs, got %s", err.Error())
+	}
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting structs, got %s", err.Error())
+	}
+	if cities[0].ID == 0 || cities[1].ID != cities[0].ID+1 {
+		t.Fatalf("Expected sequential ids to be set, got %d and %d", cities[0].ID, cities[1].ID)
+	}
+	if num := countSQLServerCities(t, "Struct One") + countSQLServerCities(t, "Struct Two"); num != 2 {
+		t.Fatalf("Expected 2 inserted cities, found %d", num)
+	}
+}
+
+func TestSQLServerInsertColumnOrder(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	values := map[string]interface{}{
+		"surname":    "Smith",
+		"first_name": "John",
+		"email":      "john@smith.com",
+	}
+	db.Table("users")
+	db.Insert(values, true)
+	query := db.GenerateInsert()
+	if !strings.Contains(query, "(email,first_name,surname)") {
+		t.Fatalf("Expected insert columns in alphabetical order, got %s", query)
+	}
+	for i := 0; i < 10; i++ {
+		repeatDb, _ := db.NewQuery()
+		repeatDb.Table("users")
+		repeatDb.Insert(values, true)
+		if repeated := repeatDb.GenerateInsert(); repeated != query {
+			t.Fatalf("Expected the same insert query each time, got %s and %s", query, repeated)
+		}
+	}
+
+	orderedDb, _ := db.NewQuery()
+	orderedDb.Table("users")
+	orderedDb.InsertOrdered([]string{
+		"surname",
+		"first_name",
+	}, []interface{}{
+		"Smith",
+		"John",
+	}, true)
+	if query := orderedDb.GenerateInsert(); !strings.Contains(query, "(surname,first_name)") {
+		t.Fatalf("Expected insert columns in the given order, got %s", query)
+	}
+}
+
+func TestSQLServerUpdateColumnOrder(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Update(map[string]interface{}{
+		"surname":    "Smith",
+		"first_name": "John",
+		"email":      "john@smith.com",
+	}, true)
+	query := db.GenerateUpdate()
+	email, firstName, surname := strings.Index(query, "email ="), strings.Index(query, "first_name ="), strings.Index(query, "surname =")
+	if email < 0 || !(email < firstName && firstName < surname) {
+		t.Fatalf("Expected update columns in alphabetical order, got %s", query)
+	}
+
+	orderedDb, _ := db.NewQuery()
+	orderedDb.Table("users")
+	orderedDb.UpdateOrdered([]string{
+		"surname",
+		"first_name",
+	}, []interface{}{
+		"Smith",
+		"John",
+	}, true)
+	query = orderedDb.GenerateUpdate()
+	if strings.Index(query, "surname =") > strings.Index(query, "first_name =") {
+		t.Fatalf("Expected update columns in the given order, got %s", query)
+	}
+}
+
+func fetchSQLServerPostcodes(t *testing.T, db DB) []string {
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching grouped results, got %s", err.Error())
+	}
+	defer close()
+	postcodes := []string{}
+	for res.Next() {
+		var (
+			postcode string
+			num      int
+		)
+		res.Scan(&postcode, &num)
+		postcodes = append(postcodes, postcode)
+	}
+	return postcodes
+}
+
+func TestSQLServerHaving(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"postcode",
+		db.Count("*", "num"),
+	})
+	db.Where("title_id", "=", 1, true)
+	db.GroupBy("postcode")
+	db.Having("COUNT(*)", ">", 1, true)
+	db.OrderBy("postcode", "ASC")
+	if postcodes := fetchSQLServerPostcodes(t, db); strings.Join(postcodes, ",") != "DE71 AXC" {
+		t.Fatalf("Expected only DE71 AXC to have more than one user, got %v", postcodes)
+	}
+
+	orDb, _ := db.Clone()
+	orDb.OrHaving("postcode", "=", "ST54 POC", true)
+	if postcodes := fetchSQLServerPostcodes(t, orDb); strings.Join(postcodes, ",") != "DE71 AXC,ST54 POC" {
+		t.Fatalf("Expected DE71 AXC and ST54 POC, got %v", postcodes)
+	}
+}
+
+func TestSQLServerHavingBrackets(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"postcode",
+		db.Count("*", "num"),
+	})
+	db.GroupBy("postcode")
+	db.Having("COUNT(*)", ">", 5, true)
+	db.OrOpenHavingBracket()
+	db.HavingIn("postcode", []interface{}{"DE76 YAS", "DE71 AXC"}, true)
+	db.Having("COUNT(*)", "=", 1, true)
+	db.CloseHavingBracket()
+	db.OrderBy("postcode", "ASC")
+	if postcodes := fetchSQLServerPostcodes(t, db); strings.Join(postcodes, ",") != "DE76 YAS" {
+		t.Fatalf("Expected only DE76 YAS, got %v", postcodes)
+	}
+}
+
+func fetchSQLServerFirstNames(t *testing.T, db DB) []string {
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching names, got %s", err.Error())
+	}
+	defer close()
+	names := []string{}
+	for res.Next() {
+		var name string
+		res.Scan(&name)
+		names = append(names, name)
+	}
+	return names
+}
+
+func newSQLServerNameQuery(t *testing.T, db DB, field string, values ...interface{}) DB {
+	nameDb, err := db.NewQuery()
+	if err != nil {
+		t.Fatalf("Failed creating query, got %s", err.Error())
+	}
+	nameDb.Table("users")
+	nameDb.Cols([]string{
+		"first_name",
+	})
+	nameDb.WhereInList(field, values, true)
+	return nameDb
+}
+
+func TestSQLServerUnion(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	unionDb := newSQLServerNameQuery(t, db, "first_name", "Steve")
+	unionDb.Union(newSQLServerNameQuery(t, db, "first_name", "Bob", "Steve"))
+	unionDb.OrderBy("first_name", "ASC")
+	if names := fetchSQLServerFirstNames(t, unionDb); strings.Join(names, ",") != "Bob,Steve" {
+		t.Fatalf("Expected Bob and Steve once each, got %v", names)
+	}
+
+	unionAllDb := newSQLServerNameQuery(t, db, "first_name", "Steve")
+	unionAllDb.UnionAll(newSQLServerNameQuery(t, db, "first_name", "Bob", "Steve"))
+	unionAllDb.OrderBy("first_name", "ASC")
+	if names := fetchSQLServerFirstNames(t, unionAllDb); strings.Join(names, ",") != "Bob,Steve,Steve" {
+		t.Fatalf("Expected Steve to be included twice, got %v", names)
+	}
+}
+
+func TestSQLServerIntersectAndExcept(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	intersectDb := newSQLServerNameQuery(t, db, "surname", "Briar", "Jones")
+	intersectDb.Intersect(newSQLServerNameQuery(t, db, "first_name", "Bob", "Steve"))
+	if names := fetchSQLServerFirstNames(t, intersectDb); strings.Join(names, ",") != "Bob" {
+		t.Fatalf("Expected only Bob, got %v", names)
+	}
+
+	exceptDb := newSQLServerNameQuery(t, db, "first_name", "Bob", "Sharon", "Steve")
+	exceptDb.Except(newSQLServerNameQuery(t, db, "first_name", "Bob"))
+	exceptDb.OrderBy("first_name", "DESC")
+	if names := fetchSQLServerFirstNames(t, exceptDb); strings.Join(names, ",") != "Steve,Sharon" {
+		t.Fatalf("Expected Steve and Sharon, got %v", names)
+	}
+}
+
+func TestSQLServerWith(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	activeDb, _ := db.NewQuery()
+	activeDb.Table("users")
+	activeDb.Cols([]string{
+		"first_name",
+		"surname",
+	})
+	activeDb.Where("active", "=", 1, true)
+
+	db.With("active_users", activeDb)
+	db.Table("active_users")
+	db.Cols([]string{
+		"first_name",
+	})
+	db.Where("surname", "!=", "Jones", true)
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected only Steve, got %v", names)
+	}
+}
+
+func TestSQLServerWithRecursive(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	anchorDb, _ := db.NewQuery()
+	anchorDb.Table("cities")
+	anchorDb.Cols([]string{
+		"1 n",
+	})
+	anchorDb.Where("city", "=", "Derby", true)
+
+	recursiveDb, _ := db.NewQuery()
+	recursiveDb.Table("numbers")
+	recursiveDb.Cols([]string{
+		"n + 1 n",
+	})
+	recursiveDb.Where("n", "<", 5, true)
+
+	db.WithRecursive("numbers", anchorDb, recursiveDb)
+	db.Table("numbers")
+	db.Cols([]string{
+		"n",
+	})
+	db.Where("n", ">", 2, true)
+	db.OrderBy("n", "ASC")
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching recursive query, got %s", err.Error())
+	}
+	defer close()
+	numbers := []int{}
+	for res.Next() {
+		var n int
+		res.Scan(&n)
+		numbers = append(numbers, n)
+	}
+	if fmt.Sprint(numbers) != "[3 4 5]" {
+		t.Fatalf("Expected 3 to 5, got %v", numbers)
+	}
+}
+
+func TestSQLServerUpdateWith(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("With Update", "With Updated")
+	db.Table("cities")
+	db.Insert(map[string]interface{}{
+		"city": "With Update",
+	}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting city, got %s", err.Error())
+	}
+
+	renameDb, _ := db.NewQuery()
+	renameDb.Table("users")
+	renameDb.Cols([]string{
+		"'With Update' city",
+	})
+	renameDb.Where("first_name", "=", "Steve", true)
+
+	cteDb, _ := db.NewQuery()
+	cteDb.Table("renames")
+	cteDb.Cols([]string{
+		"city",
+	})
+
+	updateDb, _ := db.NewQuery()
+	updateDb.With("renames", renameDb)
+	updateDb.Table("cities")
+	updateDb.Update(map[string]interface{}{
+		"city": "With Updated",
+	}, true)
+	updateDb.WhereInSub("city", cteDb)
+	if _, err := updateDb.Save(); err != nil {
+		t.Fatalf("Failed updating with a common table expression, got %s", err.Error())
+	}
+	if num := countSQLServerCities(t, "With Updated"); num != 1 {
+		t.Fatalf("Expected the city to be updated, found %d", num)
+	}
+}
+
+func TestSQLServerWindowFunctions(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"first_name",
+		db.Window(RowNumber().Over([]string{"postcode"}, []string{"first_name ASC"}, ""), "row_num"),
+		db.Window(Lag("first_name", 1).Over(nil, []string{"first_name"}, ""), "previous_name"),
+		db.Window(Sum("title_id").Over(nil, []string{"first_name"}, FrameRunningTotal), "running_total"),
+		db.Window(Count("*").Over([]string{"postcode"}, nil, ""), "postcode_users"),
+	})
+	db.OrderBy("first_name", "ASC")
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching window functions, got %s", err.Error())
+	}
+	defer close()
+	results := []string{}
+	for res.Next() {
+		var (
+			firstName     string
+			rowNum        int
+			previousName  sql.NullString
+			runningTotal  int
+			postcodeUsers int
+		)
+		res.Scan(&firstName, &rowNum, &previousName, &runningTotal, &postcodeUsers)
+		results = append(results, fmt.Sprintf("%s %d %s %d %d", firstName, rowNum, previousName.String, runningTotal, postcodeUsers))
+	}
+	expected := []string{
+		"Bob 1  1 2",
+		"Juliet 1 Bob 2 1",
+		"Sharon 2 Juliet 3 2",
+		"Steve 1 Sharon 4 1",
+	}
+	if strings.Join(results, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestSQLServerWindowWithoutOrder(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		db.Window(RowNumber().Over(nil, nil, ""), "row_num"),
+	})
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching window function without ordering, got %s", err.Error())
+	}
+	defer close()
+	total := 0
+	for res.Next() {
+		var rowNum int
+		res.Scan(&rowNum)
+		total += rowNum
+	}
+	if total != 10 {
+		t.Fatalf("Expected row numbers 1 to 4, got a total of %d", total)
+	}
+}
+
+func TestSQLServerCrossJoin(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("genders")
+	db.CrossJoinAs("genders", "other_genders")
+	db.Cols([]string{
+		db.Count("*", "num"),
+	})
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching cross join, got %s", err.Error())
+	}
+	defer close()
+	var num int
+	for res.Next() {
+		res.Scan(&num)
+	}
+	if num != 4 {
+		t.Fatalf("Expected every gender joined to every gender, got %d rows", num)
+	}
+}
+
+func TestSQLServerRightJoinAs(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("genders")
+	db.RightJoinTableAs("users", "u", "u.gender_id", "genders.id")
+	db.Cols([]string{
+		"u.first_name",
+	})
+	db.OrderBy("u.first_name", "ASC")
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Bob,Juliet,Sharon,Steve" {
+		t.Fatalf("Expected every user, got %v", names)
+	}
+}
+
+func TestSQLServerFullJoinAs(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	insertDb, _ := db.NewQuery()
+	insertDb.Table("genders")
+	insertDb.Insert(map[string]interface{}{
+		"gender": "Other",
+	}, true)
+	if _, err := insertDb.Save(); err != nil {
+		t.Fatalf("Failed inserting gender, got %s", err.Error())
+	}
+	defer func() {
+		deleteDb, _ := db.NewQuery()
+		deleteDb.Table("genders")
+		deleteDb.Where("gender", "=", "Other", true)
+		deleteDb.Delete()
+	}()
+
+	db.Table("users")
+	db.FullJoinTableAs("genders", "g", "g.id", "users.gender_id")
+	db.Cols([]string{
+		"users.first_name",
+		"g.gender",
+	})
+	db.Where("g.gender", "!=", "Male", true)
+	res, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching full join, got %s", err.Error())
+	}
+	defer close()
+	rows := []string{}
+	for res.Next() {
+		var (
+			firstName sql.NullString
+			gender    sql.NullString
+		)
+		res.Scan(&firstName, &gender)
+		rows = append(rows, firstName.String+" "+gender.String)
+	}
+	sort.Strings(rows)
+	if strings.Join(rows, ",") != " Other,Juliet Female,Sharon Female" {
+		t.Fatalf("Expected the users and the gender without users, got %v", rows)
+	}
+}
+
+func TestSQLServerCrossAndOuterApply(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	firstUserDb, _ := db.NewQuery()
+	firstUserDb.Table("users")
+	firstUserDb.Cols([]string{
+		"first_name",
+	})
+	firstUserDb.Where("users.gender_id", "=", "genders.id", false)
+	firstUserDb.Where("users.active", "=", 1, true)
+	firstUserDb.OrderBy("first_name", "ASC")
+	firstUserDb.LimitBy(1)
+
+	//both queries use the default parameter prefix so the sub query's parameters have to be renamed
+	db.Table("genders")
+	db.CrossApply(firstUserDb, "first_user")
+	db.Cols([]string{
+		"first_user.first_name",
+	})
+	db.Where("genders.gender", "!=", "Unknown", true)
+	db.OrderBy("genders.gender", "ASC")
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Juliet,Steve" {
+		t.Fatalf("Expected the first active user of each gender, got %v", names)
+	}
+
+	outerDb, _ := db.NewQuery()
+	outerDb.Table("genders")
+	outerDb.OuterApply(firstUserDb, "first_user")
+	outerDb.Cols([]string{
+		"genders.gender",
+	})
+	outerDb.Where("genders.gender", "=", "Male", true)
+	if names := fetchSQLServerFirstNames(t, outerDb); strings.Join(names, ",") != "Male" {
+		t.Fatalf("Expected the gender to be kept, got %v", names)
+	}
+}
+
+func newSQLServerUserNamesQuery(t *testing.T) DB {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("users")
+	db.Cols([]string{
+		"first_name",
+	})
+	db.OrderBy("first_name", "ASC")
+	return db
+}
+
+func TestSQLServerWhereBetween(t *testing.T) {
+	db := newSQLServerUserNamesQuery(t)
+	db.WhereBetween("date_of_birth", "1980-01-01", "1995-12-31")
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Juliet,Steve" {
+		t.Fatalf("Expected Juliet and Steve, got %v", names)
+	}
+
+	notDb := newSQLServerUserNamesQuery(t)
+	notDb.WhereNotBetween("date_of_birth", "1980-01-01", "1995-12-31")
+	if names := fetchSQLServerFirstNames(t, notDb); strings.Join(names, ",") != "Bob,Sharon" {
+		t.Fatalf("Expected Bob and Sharon, got %v", names)
+	}
+}
+
+func TestSQLServerWhereLike(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("100%_Real", "100 Real")
+	db.Table("cities")
+	db.InsertMulti([]string{
+		"city",
+	}, [][]interface{}{
+		{"100%_Real"},
+		{"100 Real"},
+	}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting cities, got %s", err.Error())
+	}
+
+	likeDb, _ := db.NewQuery()
+	likeDb.Table("cities")
+	likeDb.Cols([]string{
+		"city",
+	})
+	likeDb.WhereLike("city", EscapeLike("100%_")+"%")
+	if cities := fetchSQLServerFirstNames(t, likeDb); strings.Join(cities, ",") != "100%_Real" {
+		t.Fatalf("Expected the wildcards to be matched literally, got %v", cities)
+	}
+
+	iLikeDb := newSQLServerUserNamesQuery(t)
+	iLikeDb.WhereILike("first_name", "ST%")
+	if names := fetchSQLServerFirstNames(t, iLikeDb); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected Steve, got %v", names)
+	}
+}
+
+func TestSQLServerWhereExists(t *testing.T) {
+	countryDb, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	countryDb.Table("countries")
+	countryDb.Cols([]string{
+		"id",
+	})
+	countryDb.WhereColumn("countries.id", "=", "users.country_id")
+	countryDb.Where("countries.country", "=", "United Kingdom", true)
+
+	//both queries use the default parameter prefix so the sub query's parameters have to be renamed
+	db := newSQLServerUserNamesQuery(t)
+	db.Where("first_name", "!=", "Nobody", true)
+	db.WhereExists(countryDb)
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Juliet,Sharon,Steve" {
+		t.Fatalf("Expected the users with a country, got %v", names)
+	}
+
+	notDb := newSQLServerUserNamesQuery(t)
+	notDb.WhereNotExists(countryDb)
+	if names := fetchSQLServerFirstNames(t, notDb); strings.Join(names, ",") != "Bob" {
+		t.Fatalf("Expected Bob, got %v", names)
+	}
+}
+
+func TestSQLServerWhereColumn(t *testing.T) {
+	db := newSQLServerUserNamesQuery(t)
+	db.WhereColumn("gender_id", "=", "title_id")
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Bob,Steve" {
+		t.Fatalf("Expected Bob and Steve, got %v", names)
+	}
+}
+
+func TestSQLServerWhereDateParts(t *testing.T) {
+	db := newSQLServerUserNamesQuery(t)
+	db.WhereDate("date_of_birth", "=", time.Date(1993, time.July, 12, 0, 0, 0, 0, time.UTC))
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected Steve, got %v", names)
+	}
+
+	yearDb := newSQLServerUserNamesQuery(t)
+	yearDb.WhereYear("date_of_birth", ">=", 1990)
+	if names := fetchSQLServerFirstNames(t, yearDb); strings.Join(names, ",") != "Bob,Steve" {
+		t.Fatalf("Expected Bob and Steve, got %v", names)
+	}
+
+	monthDb := newSQLServerUserNamesQuery(t)
+	monthDb.WhereMonth("date_of_birth", "=", 3)
+	if names := fetchSQLServerFirstNames(t, monthDb); strings.Join(names, ",") != "Sharon" {
+		t.Fatalf("Expected Sharon, got %v", names)
+	}
+}
+
+func TestSQLServerWhereGroup(t *testing.T) {
+	db := newSQLServerUserNamesQuery(t)
+	db.Where("active", "=", 1, true)
+	db.WhereGroup(func(q *Query) {
+		q.Where("first_name", "=", "Steve", true)
+		q.Or()
+		q.Where("first_name", "=", "Bob", true)
+	})
+	db.Where("surname", "!=", "Jones", true)
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Steve" {
+		t.Fatalf("Expected only Steve, got %v", names)
+	}
+
+	orDb := newSQLServerUserNamesQuery(t)
+	orDb.Where("first_name", "=", "Bob", true)
+	orDb.OrWhereGroup(func(q *Query) {
+		q.Where("active", "=", 1, true)
+		q.Where("surname", "=", "Jones", true)
+	})
+	if names := fetchSQLServerFirstNames(t, orDb); strings.Join(names, ",") != "Bob,Juliet" {
+		t.Fatalf("Expected Bob and Juliet, got %v", names)
+	}
+}
+
+func TestSQLServerOrWhere(t *testing.T) {
+	db := newSQLServerUserNamesQuery(t)
+	db.Where("first_name", "=", "Sharon", true)
+	db.OrWhere("first_name", "=", "Bob", true)
+	db.OrWhere("first_name", "=", "Juliet", true)
+	db.Where("active", "=", 0, true)
+	//AND takes precedence so only the last OR condition is combined with active
+	if names := fetchSQLServerFirstNames(t, db); strings.Join(names, ",") != "Bob,Sharon" {
+		t.Fatalf("Expected Bob and Sharon, got %v", names)
+	}
+}
+
+func TestSQLServerUnbalancedBrackets(t *testing.T) {
+	db := newSQLServerUserNamesQuery(t)
+	db.OpenBracket()
+	db.Where("first_name", "=", "Steve", true)
+	if _, _, err := db.Fetch(); err == nil || !strings.Contains(err.Error(), "not closed") {
+		t.Fatalf("Expected an unclosed bracket error, got %v", err)
+	}
+
+	closeDb := newSQLServerUserNamesQuery(t)
+	closeDb.Where("first_name", "=", "Steve", true)
+	closeDb.CloseBracket()
+	if err := closeDb.Validate(); err == nil {
+		t.Fatal("Expected an error closing a bracket that wasn't opened")
+	}
+
+	emptyDb := newSQLServerUserNamesQuery(t)
+	emptyDb.Where("first_name", "=", "Steve", true)
+	emptyDb.WhereGroup(func(q *Query) {})
+	if err := emptyDb.Validate(); err == nil {
+		t.Fatal("Expected an error for an empty group")
+	}
+}
+
+func fetchSQLServerUpsertNames(t *testing.T) map[string]string {
+	db, _ := Open("sqlserver_test")
+	db.Table("upsert_test")
+	db.Cols([]string{"code", "name"})
+	results, close, err := db.Fetch()
+	if err != nil {
+		t.Fatalf("Failed fetching upserted rows, got %s", err.Error())
+	}
+	defer close()
+	names := map[string]string{}
+	for results.Next() {
+		var code, name string
+		results.Scan(&code, &name)
+		names[code] = name
+	}
+	return names
+}
+
+func TestSQLServerUpsert(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	params := []interface{}{}
+	if exists, err := db.DoesTableExist("upsert_test"); err == nil && !exists {
+		db.RawNonQuery("CREATE TABLE upsert_test (code VARCHAR(20) NOT NULL PRIMARY KEY, name VARCHAR(50) NOT NULL)", params)
+	}
+	defer db.RawNonQuery("DROP TABLE upsert_test", params)
+
+	insertDb, _ := db.NewQuery()
+	insertDb.Table("upsert_test")
+	insertDb.Upsert(map[string]interface{}{
+		"code": "A",
+		"name": "First",
+	}, []string{"code"}, nil)
+	if _, err := insertDb.Save(); err != nil {
+		t.Fatalf("Failed upserting new row, got %s", err.Error())
+	}
+
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("upsert_test")
+	updateDb.Upsert(map[string]interface{}{
+		"code": "A",
+		"name": "Updated",
+	}, []string{"code"}, []string{"name"})
+	if _, err := updateDb.Save(); err != nil {
+		t.Fatalf("Failed upserting existing row, got %s", err.Error())
+	}
+	if names := fetchSQLServerUpsertNames(t); len(names) != 1 || names["A"] != "Updated" {
+		t.Fatalf("Expected A to be updated, got %v", names)
+	}
+
+	multiDb, _ := db.NewQuery()
+	multiDb.Table("upsert_test")
+	multiDb.UpsertMulti([]string{
+		"code",
+		"name",
+	}, [][]interface{}{
+		{"A", "Again"},
+		{"B", "Second"},
+	}, []string{"code"}, nil)
+	if _, err := multiDb.Save(); err != nil {
+		t.Fatalf("Failed upserting multiple rows, got %s", err.Error())
+	}
+	names := fetchSQLServerUpsertNames(t)
+	if len(names) != 2 || names["A"] != "Again" || names["B"] != "Second" {
+		t.Fatalf("Expected A updated and B inserted, got %v", names)
+	}
+
+	keyDb, _ := db.NewQuery()
+	keyDb.Table("upsert_test")
+	keyDb.Upsert(map[string]interface{}{
+		"code": "B",
+		"name": "Keyed",
+	}, nil, []string{"name"})
+	if _, err := keyDb.Save(); err == nil {
+		t.Fatalf("Expected an error upserting without conflict columns")
+	}
+}
+
+func TestSQLServerInsertFrom(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Steve", "Bob")
+	source, _ := db.NewQuery()
+	source.Table("users")
+	source.Cols([]string{"first_name"})
+	source.WhereInList("first_name", []interface{}{"Steve", "Bob"}, true)
+
+	db.Table("cities")
+	db.InsertFrom([]string{"city"}, source)
+	res, err := db.Save()
+	if err != nil {
+		t.Fatalf("Failed inserting from select, got %s", err.Error())
+	}
+	if num, _ := res.RowsAffected(); num != 2 {
+		t.Fatalf("Expected 2 affected rows, got %d", num)
+	}
+	if countSQLServerCities(t, "Steve") != 1 || countSQLServerCities(t, "Bob") != 1 {
+		t.Fatalf("Expected the selected names to be inserted as cities")
+	}
+}
+
+func insertSQLServerUserCities(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.InsertMulti([]string{"city"}, [][]interface{}{
+		{"Steve"},
+		{"Bob"},
+	}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed inserting cities, got %s", err.Error())
+	}
+}
+
+func TestSQLServerUpdateJoin(t *testing.T) {
+	insertSQLServerUserCities(t)
+	defer deleteSQLServerCities("Steve", "Bob", "Active Steve")
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	activeUsers, _ := db.NewQuery()
+	activeUsers.SetParamPrefix("active")
+	activeUsers.Table("users")
+	activeUsers.Cols([]string{"first_name"})
+	activeUsers.Where("active", "=", 1, true)
+
+	db.Table("cities")
+	db.JoinSub(activeUsers, "active_users", "active_users.first_name", "cities.city")
+	db.Update(map[string]interface{}{
+		"city": "Active Steve",
+	}, true)
+	db.WhereInList("cities.city", []interface{}{"Steve", "Bob"}, true)
+	if _, err := db.Save(); err != nil {
+		t.Fatalf("Failed running update with join, got %s", err.Error())
+	}
+	if countSQLServerCities(t, "Active Steve") != 1 || countSQLServerCities(t, "Bob") != 1 {
+		t.Fatalf("Expected only the city joined to an active user to be updated")
+	}
+}
+
+func TestSQLServerDeleteJoin(t *testing.T) {
+	insertSQLServerUserCities(t)
+	defer deleteSQLServerCities("Steve", "Bob")
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.JoinTable("users", "users.first_name", "cities.city")
+	db.Where("users.active", "=", 0, true)
+	if _, err := db.Delete(); err != nil {
+		t.Fatalf("Failed running delete with join, got %s", err.Error())
+	}
+	if countSQLServerCities(t, "Steve") != 1 || countSQLServerCities(t, "Bob") != 0 {
+		t.Fatalf("Expected only the city joined to an inactive user to be deleted")
+	}
+}
+
+func TestSQLServerSaveReturning(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Returning One", "Returning Two", "Returning Three")
+	db.Table("cities")
+	db.InsertMulti([]string{"city"}, [][]interface{}{
+		{"Returning One"},
+		{"Returning Two"},
+	}, true)
+	db.Returning("id", "city")
+	results, close, err := db.SaveReturning()
+	if err != nil {
+		t.Fatalf("Failed inserting with returning columns, got %s", err.Error())
+	}
+	ids := map[string]int{}
+	for results.Next() {
+		var id int
+		var city string
+		results.Scan(&id, &city)
+		ids[city] = id
+	}
+	close()
+	if len(ids) != 2 || ids["Returning One"] == 0 || ids["Returning Two"] == 0 || ids["Returning One"] == ids["Returning Two"] {
+		t.Fatalf("Expected an id for each inserted city, got %v", ids)
+	}
+
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("cities")
+	updateDb.Update(map[string]interface{}{
+		"city": "Returning Three",
+	}, true)
+	updateDb.Where("id", "=", ids["Returning Two"], true)
+	updateDb.Returning("city")
+	results, close, err = updateDb.SaveReturning()
+	if err != nil {
+		t.Fatalf("Failed updating with returning columns, got %s", err.Error())
+	}
+	updated := []string{}
+	for results.Next() {
+		var city string
+		results.Scan(&city)
+		updated = append(updated, city)
+	}
+	close()
+	if strings.Join(updated, ",") != "Returning Three" {
+		t.Fatalf("Expected the updated city to be returned, got %v", updated)
+	}
+
+	deleteDb, _ := db.NewQuery()
+	deleteDb.Table("cities")
+	deleteDb.Where("id", "=", ids["Returning One"], true)
+	deleteDb.Returning("city")
+	results, close, err = deleteDb.DeleteReturning()
+	if err != nil {
+		t.Fatalf("Failed deleting with returning columns, got %s", err.Error())
+	}
+	deleted := []string{}
+	for results.Next() {
+		var city string
+		results.Scan(&city)
+		deleted = append(deleted, city)
+	}
+	close()
+	if strings.Join(deleted, ",") != "Returning One" || countSQLServerCities(t, "Returning One") != 0 {
+		t.Fatalf("Expected the deleted city to be returned, got %v", deleted)
+	}
+}
+
+func TestSQLServerLockOutsideTransaction(t *testing.T) {
+	db := newSQLServerUserNamesQuery(t)
+	db.LockForUpdate()
+	if _, _, err := db.Fetch(); err == nil {
+		t.Fatalf("Expected an error locking rows outside of a transaction")
+	}
+}
+
+func TestSQLServerLockForUpdate(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	names := []string{}
+	err = db.Transaction(func(tx Tx) error {
+		lockDb, err := tx.NewQuery()
+		if err != nil {
+			return err
+		}
+		lockDb.Table("users")
+		lockDb.Cols([]string{
+			"first_name",
+		})
+		lockDb.Where("active", "=", 1, true)
+		lockDb.OrderBy("first_name", "ASC")
+		lockDb.LockForUpdate()
+		lockDb.SkipLocked()
+		results, close, err := lockDb.Fetch()
+		if err != nil {
+			return err
+		}
+		defer close()
+		for results.Next() {
+			var name string
+			results.Scan(&name)
+			names = append(names, name)
+		}
+		return results.Err()
+	})
+	if err != nil {
+		t.Fatalf("Failed fetching locked rows, got %s", err.Error())
+	}
+	if strings.Join(names, ",") != "Juliet,Steve" {
+		t.Fatalf("Expected the active users, got %v", names)
+	}
+}
+
+func TestSQLServerAcquireLock(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	ctx := context.Background()
+	lock, err := db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring lock, got %s", err.Error())
+	}
+	if _, err := db.AcquireLock(ctx, "bezsql_test_lock", 200*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Expected ErrLockTimeout while the lock is held, got %v", err)
+	}
+	if err := lock.ReleaseLock(ctx); err != nil {
+		t.Fatalf("Failed releasing lock, got %s", err.Error())
+	}
+	lock, err = db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring released lock, got %s", err.Error())
+	}
+	if err := lock.ReleaseLock(ctx); err != nil {
+		t.Fatalf("Failed releasing lock, got %s", err.Error())
+	}
+}
+
+func TestSQLServerOrderedValueCount(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	db.Table("cities")
+	db.InsertOrdered([]string{"id", "city"}, []interface{}{"Missing Id"}, true)
+	if _, err := db.Save(); err == nil {
+		t.Fatalf("Expected an error inserting fewer values than columns")
+	}
+
+	updateDb, _ := db.NewQuery()
+	updateDb.Table("cities")
+	updateDb.UpdateOrdered([]string{"city"}, []interface{}{"Extra", "Value"}, true)
+	updateDb.Where("city", "=", "Extra", true)
+	if err := updateDb.Validate(); err == nil {
+		t.Fatalf("Expected an error updating with more values than columns")
+	}
+}
+
+func TestSQLServerReleaseLockCancelled(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	ctx := context.Background()
+	lock, err := db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring lock, got %s", err.Error())
+	}
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := lock.ReleaseLock(cancelledCtx); err == nil {
+		t.Fatalf("Expected an error releasing a lock with a cancelled context")
+	}
+	//the connection holding the lock is closed rather than returned to the pool, ending its session
+	lock, err = db.AcquireLock(ctx, "bezsql_test_lock", time.Second)
+	if err != nil {
+		t.Fatalf("Failed acquiring the lock of a closed connection, got %s", err.Error())
+	}
+	if err := lock.ReleaseLock(ctx); err != nil {
+		t.Fatalf("Failed releasing lock, got %s", err.Error())
+	}
+}
+
+func TestSQLServerAcquireLockTimeoutNotNegative(t *testing.T) {
+	if _, err := newBuilder(&sQLServer{}).AcquireLock(context.Background(), "bezsql_test_lock", -time.Second); err == nil {
+		t.Fatalf("Expected an error acquiring a lock with a negative timeout")
+	}
+	_, params, _ := (&sQLServer{}).AcquireLockQuery("bezsql_test_lock", lockTimeRemaining(time.Now().Add(-time.Second)))
+	if timeout := params[1].(int64); timeout < 0 {
+		t.Fatalf("Expected a timeout of at least 0 once the deadline has passed, got %d", timeout)
+	}
+}
+
+func TestSQLServerInsertStructsReturning(t *testing.T) {
+	db, err := Open("sqlserver_test")
+	if err != nil {
+		t.Fatalf("Failed opening database, got %s", err.Error())
+	}
+	defer deleteSQLServerCities("Returning One", "Returning Two")
+	cities := []structSQLServerCity{
+		{City: "Returning One"},
+		{City: "Returning Two"},
+	}
+	db.Table("cities")
+	if err := db.InsertStructs(cities); err != nil {
+		t.Fatalf("Failed setting insert from structs, got %s", err.Error())
+	}
+	db.Returning("id")
+	res, err := db.Save()
+	if err != nil {
+		t.Fatalf("Failed inserting structs, got %s", err.Error())
+	}
+	if cities[0].ID == 0 || cities[1].ID != cities[0].ID+1 {
+		t.Fatalf("Expected sequential ids to be set, got %d and %d", cities[0].ID, cities[1].ID)
+	}
+	if id, err := res.LastInsertId(); err != nil || id != int64(cities[0].ID) {
+		t.Fatalf("Expected the last insert id to be the first city's id, got %d %v", id, err)
+	}
+}
@@ -0,0 +1,282 @@
+package bezsql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// a struct field mapped to a column using its db tag, e.g. `db:"id,pk"` or `db:"email,omitempty"`
+type structField struct {
+	column string
+	index  []int
+	//the field is left out of inserts when zero and set from the inserted id after saving
+	primaryKey bool
+	//the field is left out of inserts and updates when zero
+	omitEmpty bool
+}
+
+// returns the mapped fields of a struct in order, fields of embedded structs are included as if they were on
+// the outer struct unless the outer struct already has a field for the same column
+func mappedFields(structType reflect.Type) []structField {
+	return collectFields(structType, nil)
+}
+
+func collectFields(structType reflect.Type, parent []int) []structField {
+	fields := []structField{}
+	embedded := [][]structField{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		index := append(append([]int{}, parent...), i)
+		options := strings.Split(field.Tag.Get("db"), ",")
+		if options[0] == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && options[0] == "" && fieldType.Kind() == reflect.Struct {
+			embedded = append(embedded, collectFields(fieldType, index))
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		mapped := structField{
+			column: options[0],
+			index:  index,
+		}
+		if mapped.column == "" {
+			mapped.column = snakeCase(field.Name)
+		}
+		for _, option := range options[1:] {
+			switch option {
+			case "pk":
+				mapped.primaryKey = true
+			case "omitempty":
+				mapped.omitEmpty = true
+			}
+		}
+		fields = append(fields, mapped)
+	}
+	for _, embeddedFields := range embedded {
+		for _, embeddedField := range embeddedFields {
+			exists := false
+			for _, field := range fields {
+				if strings.EqualFold(field.column, embeddedField.column) {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				fields = append(fields, embeddedField)
+			}
+		}
+	}
+	return fields
+}
+
+// returns the struct a value points to, or the value itself if it isn't a pointer
+func structValue(v interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return value, fmt.Errorf("expected a struct, got %T", v)
+	}
+	return value, nil
+}
+
+// reads an existing field value without creating nil embedded struct pointers, nil embedded fields count as zero
+func readField(row reflect.Value, index []int) (reflect.Value, bool) {
+	value := row
+	for i, fieldNum := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return value, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldNum)
+	}
+	return value, true
+}
+
+func fieldInterface(row reflect.Value, index []int) interface{} {
+	value, ok := readField(row, index)
+	if !ok {
+		return nil
+	}
+	return value.Interface()
+}
+
+func isZeroField(row reflect.Value, index []int) bool {
+	value, ok := readField(row, index)
+	return !ok || value.IsZero()
+}
+
+// sets the values to insert from the db tagged fields of a struct, if v is a pointer its primary key field
+// is set to the inserted id by Save
+func (db *builder) InsertStruct(v interface{}) error {
+	row, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	rows := []reflect.Value{row}
+	return db.insertStructRows(row.Type(), rows)
+}
+
+// sets multiple rows to insert from a slice of structs or struct pointers, primary key fields are set to the
+// inserted ids by Save, fields tagged omitempty are only left out if they are zero in every row
+func (db *builder) InsertStructs(slice interface{}) error {
+	sliceValue := reflect.ValueOf(slice)
+	if sliceValue.Kind() == reflect.Ptr {
+		sliceValue = sliceValue.Elem()
+	}
+	if sliceValue.Kind() != reflect.Slice {
+		return fmt.Errorf("expected a slice of structs, got %T", slice)
+	}
+	if sliceValue.Len() == 0 {
+		return errors.New("no structs to insert")
+	}
+	rows := []reflect.Value{}
+	for i := 0; i < sliceValue.Len(); i++ {
+		row, err := structValue(sliceValue.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		if sliceValue.Index(i).Kind() == reflect.Struct {
+			row = sliceValue.Index(i)
+		}
+		rows = append(rows, row)
+	}
+	return db.insertStructRows(rows[0].Type(), rows)
+}
+
+func (db *builder) insertStructRows(structType reflect.Type, rows []reflect.Value) error {
+	columns := []string{}
+	var primaryKey *structField
+	included := []structField{}
+	for _, field := range mappedFields(structType) {
+		field := field
+		if field.primaryKey || field.omitEmpty {
+			allZero := true
+			for _, row := range rows {
+				if !isZeroField(row, field.index) {
+					allZero = false
+					break
+				}
+			}
+			if allZero {
+				if field.primaryKey {
+					primaryKey = &field
+				}
+				continue
+			}
+		}
+		columns = append(columns, field.column)
+		included = append(included, field)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("no columns to insert from %s", structType)
+	}
+
+	values := [][]interface{}{}
+	for _, row := range rows {
+		rowValues := []interface{}{}
+		for _, field := range included {
+			rowValues = append(rowValues, fieldInterface(row, field.index))
+		}
+		values = append(values, rowValues)
+	}
+	db.InsertMulti(columns, values, true)
+
+	//the ids can only be set on structs the caller can see
+	if primaryKey != nil && rows[0].CanSet() {
+		db.insertedStructs = rows
+		db.insertedKey = primaryKey.index
+	}
+	return nil
+}
+
+// sets the primary key of each inserted struct, the ids are assumed to be sequential from the first inserted id
+func (db *builder) setInsertedKeys(result sql.Result) error {
+	if len(db.insertedStructs) == 0 {
+		return nil
+	}
+	firstId, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for i, row := range db.insertedStructs {
+		field := fieldByIndex(row, db.insertedKey)
+		id := firstId + int64(i)
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			field.SetInt(id)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			field.SetUint(uint64(id))
+		default:
+			return fmt.Errorf("can't set primary key of type %s to the inserted id", field.Type())
+		}
+	}
+	return nil
+}
+
+// sets the values to update from the db tagged fields of a struct and adds a where condition on its primary key,
+// if onlyCols are given just those columns are updated
+func (db *builder) UpdateStruct(v interface{}, onlyCols ...string) error {
+	row, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	fields := mappedFields(row.Type())
+	var primaryKey *structField
+	for _, field := range fields {
+		if field.primaryKey {
+			field := field
+			primaryKey = &field
+			break
+		}
+	}
+	if primaryKey == nil {
+		return fmt.Errorf("%s has no field tagged as the primary key", row.Type())
+	}
+
+	columns := []string{}
+	values := []interface{}{}
+	if len(onlyCols) > 0 {
+		for _, col := range onlyCols {
+			found := false
+			for _, field := range fields {
+				if strings.EqualFold(field.column, col) {
+					columns = append(columns, field.column)
+					values = append(values, fieldInterface(row, field.index))
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s has no field for column %s", row.Type(), col)
+			}
+		}
+	} else {
+		for _, field := range fields {
+			if field.primaryKey || (field.omitEmpty && isZeroField(row, field.index)) {
+				continue
+			}
+			columns = append(columns, field.column)
+			values = append(values, fieldInterface(row, field.index))
+		}
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("no columns to update from %s", row.Type())
+	}
+	db.UpdateOrdered(columns, values, true)
+	db.Where(primaryKey.column, "=", fieldInterface(row, primaryKey.index), true)
+	return nil
+}
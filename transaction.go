@@ -0,0 +1,148 @@
+package bezsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"time"
+)
+
+type Tx interface {
+	//creates a query which runs as part of the transaction
+	NewQuery() (DB, error)
+	Commit() error
+	Rollback() error
+	Savepoint(name string) error
+	RollbackTo(name string) error
+}
+
+// how TransactionRetry re-runs a transaction after a deadlock or lock timeout
+type RetryPolicy struct {
+	//total number of times the transaction can be run, including the first attempt
+	MaxAttempts int
+	//delay before the first retry, doubled for each retry after
+	BaseDelay time.Duration
+	//upper limit of the delay between attempts
+	MaxDelay time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+type transaction struct {
+	db *builder
+	tx *sql.Tx
+}
+
+var savepointName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (t *transaction) NewQuery() (DB, error) {
+	return t.db.NewQuery()
+}
+
+func (t *transaction) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *transaction) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *transaction) Savepoint(name string) error {
+	if !savepointName.MatchString(name) {
+		return fmt.Errorf("invalid savepoint name %s", name)
+	}
+	_, err := t.tx.Exec(savepointQuery(t.db.dialect, name))
+	return err
+}
+
+func (t *transaction) RollbackTo(name string) error {
+	if !savepointName.MatchString(name) {
+		return fmt.Errorf("invalid savepoint name %s", name)
+	}
+	_, err := t.tx.Exec(rollbackToQuery(t.db.dialect, name))
+	return err
+}
+
+func (db *builder) Begin(ctx context.Context) (Tx, error) {
+	if db.tx != nil {
+		return nil, errors.New("query is already part of a transaction, use Savepoint to nest")
+	}
+	sqlTx, err := openConnections[db.databaseName].BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	txDb := newBuilder(db.dialect)
+	txDb.databaseName = db.databaseName
+	txDb.usedConfig = db.usedConfig
+	txDb.tx = sqlTx
+	return &transaction{
+		db: txDb,
+		tx: sqlTx,
+	}, nil
+}
+
+func (db *builder) Transaction(fn func(tx Tx) error) error {
+	return db.TransactionContext(context.Background(), fn)
+}
+
+// runs fn inside a transaction, committing when it returns nil and rolling back when it errors or panics
+func (db *builder) TransactionContext(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w, rollback failed: %s", err, rollbackErr.Error())
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// returns the delay before the given retry, a random point between half and all of the backed off delay
+// so that transactions which deadlocked each other don't retry at the same time
+func (policy RetryPolicy) delay(retry int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < retry && (policy.MaxDelay <= 0 || delay < policy.MaxDelay); i++ {
+		delay *= 2
+	}
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// runs fn inside a transaction like TransactionContext, re-running the whole function when the database reports
+// a deadlock or lock timeout, returns the number of attempts made
+func (db *builder) TransactionRetry(ctx context.Context, policy RetryPolicy, fn func(tx Tx) error) (int, error) {
+	attempts := 0
+	for {
+		attempts++
+		err := db.TransactionContext(ctx, fn)
+		if err == nil || attempts >= policy.MaxAttempts || !isRetryable(db.dialect, err) {
+			return attempts, err
+		}
+		select {
+		case <-ctx.Done():
+			return attempts, err
+		case <-time.After(policy.delay(attempts)):
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package bezsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// runs the select built on db and returns the rows scanned into T, which should be a struct or struct pointer,
+// columns are mapped to fields in the same way as FetchInto
+func Select[T any](db DB) ([]T, error) {
+	return SelectContext[T](context.Background(), db)
+}
+
+func SelectContext[T any](ctx context.Context, db DB) ([]T, error) {
+	results := []T{}
+	if err := db.FetchIntoContext(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// runs the select built on db limited to one row and returns it scanned into T, which should be a struct,
+// returns sql.ErrNoRows if nothing was found
+func First[T any](db DB) (T, error) {
+	return FirstContext[T](context.Background(), db)
+}
+
+func FirstContext[T any](ctx context.Context, db DB) (T, error) {
+	var result T
+	err := db.FirstIntoContext(ctx, &result)
+	return result, err
+}
+
+// Rows iterates over the results of a select one row at a time, scanning each into T
+type Rows[T any] struct {
+	rows       *sql.Rows
+	cancel     context.CancelFunc
+	structType reflect.Type
+	pointer    bool
+	fields     [][]int
+	current    T
+	err        error
+}
+
+// runs the select built on db and returns an iterator over the results, Close must be called once finished with
+func FetchRows[T any](db DB) (*Rows[T], error) {
+	return FetchRowsContext[T](context.Background(), db)
+}
+
+func FetchRowsContext[T any](ctx context.Context, db DB) (*Rows[T], error) {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	pointer := structType.Kind() == reflect.Ptr
+	if pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("FetchRows expects a struct type, got %s", structType)
+	}
+
+	results, cancel, err := db.FetchContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := mapColumns(results, structType)
+	if err != nil {
+		results.Close()
+		cancel()
+		return nil, err
+	}
+	return &Rows[T]{
+		rows:       results,
+		cancel:     cancel,
+		structType: structType,
+		pointer:    pointer,
+		fields:     fields,
+	}, nil
+}
+
+// scans the next row, returning false when there are no more rows or scanning fails
+func (r *Rows[T]) Next() bool {
+	if r.err != nil || !r.rows.Next() {
+		return false
+	}
+	row := reflect.New(r.structType)
+	if err := r.rows.Scan(scanTargets(row.Elem(), r.fields)...); err != nil {
+		r.err = err
+		return false
+	}
+	if r.pointer {
+		r.current = row.Interface().(T)
+	} else {
+		r.current = row.Elem().Interface().(T)
+	}
+	return true
+}
+
+// returns the row scanned by the last call to Next
+func (r *Rows[T]) Value() T {
+	return r.current
+}
+
+// returns the error, if any, that stopped iteration
+func (r *Rows[T]) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rows.Err()
+}
+
+func (r *Rows[T]) Close() error {
+	defer r.cancel()
+	return r.rows.Close()
+}
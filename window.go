@@ -0,0 +1,114 @@
+package bezsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// common window frames
+const (
+	FrameRunningTotal   = "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"
+	FrameWholePartition = "ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING"
+)
+
+// WindowFunction is a ranking, offset or aggregate function calculated over a window of rows,
+// it is added to a query's columns using Window
+type WindowFunction struct {
+	function string
+	args     []string
+	//ranking and offset functions need an ORDER BY in their window on SQL Server
+	needsOrder  bool
+	partitionBy []string
+	orderBy     []string
+	frame       string
+}
+
+func RowNumber() *WindowFunction {
+	return &WindowFunction{function: "ROW_NUMBER", needsOrder: true}
+}
+
+func Rank() *WindowFunction {
+	return &WindowFunction{function: "RANK", needsOrder: true}
+}
+
+func DenseRank() *WindowFunction {
+	return &WindowFunction{function: "DENSE_RANK", needsOrder: true}
+}
+
+// the value of col from the row offset rows before the current row
+func Lag(col string, offset int) *WindowFunction {
+	return &WindowFunction{function: "LAG", args: []string{col, fmt.Sprint(offset)}, needsOrder: true}
+}
+
+// the value of col from the row offset rows after the current row
+func Lead(col string, offset int) *WindowFunction {
+	return &WindowFunction{function: "LEAD", args: []string{col, fmt.Sprint(offset)}, needsOrder: true}
+}
+
+func Sum(col string) *WindowFunction {
+	return &WindowFunction{function: "SUM", args: []string{col}}
+}
+
+func Avg(col string) *WindowFunction {
+	return &WindowFunction{function: "AVG", args: []string{col}}
+}
+
+func Count(col string) *WindowFunction {
+	return &WindowFunction{function: "COUNT", args: []string{col}}
+}
+
+func Max(col string) *WindowFunction {
+	return &WindowFunction{function: "MAX", args: []string{col}}
+}
+
+func Min(col string) *WindowFunction {
+	return &WindowFunction{function: "MIN", args: []string{col}}
+}
+
+// sets the window the function is calculated over, orderBy entries are a field optionally followed by ASC or DESC
+// and frame is an optional frame clause such as FrameRunningTotal
+func (w *WindowFunction) Over(partitionBy []string, orderBy []string, frame string) *WindowFunction {
+	w.partitionBy = partitionBy
+	w.orderBy = orderBy
+	w.frame = frame
+	return w
+}
+
+// renders a window function as a column with an alias
+func (db *builder) Window(fn *WindowFunction, alias string) string {
+	args := []string{}
+	for i, arg := range fn.args {
+		//offsets are numbers and * isn't a field
+		if i == 0 && arg != "*" {
+			arg = db.checkReserved(arg)
+		}
+		args = append(args, arg)
+	}
+
+	window := []string{}
+	if len(fn.partitionBy) > 0 {
+		partitions := []string{}
+		for _, field := range fn.partitionBy {
+			partitions = append(partitions, db.checkReserved(field))
+		}
+		window = append(window, "PARTITION BY "+strings.Join(partitions, ", "))
+	}
+	if len(fn.orderBy) > 0 {
+		ordering := []string{}
+		for _, order := range fn.orderBy {
+			orderParts := strings.Fields(order)
+			if len(orderParts) == 0 {
+				continue
+			}
+			orderParts[0] = db.checkReserved(orderParts[0])
+			ordering = append(ordering, strings.Join(orderParts, " "))
+		}
+		window = append(window, "ORDER BY "+strings.Join(ordering, ", "))
+	} else if fn.needsOrder {
+		window = append(window, "ORDER BY (SELECT NULL)")
+	}
+	if fn.frame != "" {
+		window = append(window, fn.frame)
+	}
+	return fmt.Sprintf("%s(%s) OVER (%s) %s", fn.function, strings.Join(args, ", "), strings.Join(window, " "), db.checkReserved(alias))
+}